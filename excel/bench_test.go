@@ -0,0 +1,179 @@
+package excel
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type benchRow struct {
+	Id       int       `x-read:"编号"`
+	Name     string    `x-read:"姓名"`
+	Age      uint8     `x-read:"年龄"`
+	Score    float64   `x-read:"成绩"`
+	Passed   bool      `x-read:"通过"`
+	Birthday time.Time `x-read:"生日"`
+	Address
+	Tags []string `x-read:"标签;split=,"`
+}
+
+// the workbook of n rows of benchRow
+func newBenchFile(b *testing.B, n int) *excelize.File {
+	f := excelize.NewFile()
+	b.Cleanup(func() { f.Close() })
+	sw, err := f.NewStreamWriter("Sheet1")
+	if err != nil {
+		b.Fatal(err)
+	}
+	_ = sw.SetRow("A1", []interface{}{"编号", "姓名", "年龄", "成绩", "通过", "生日", "省份", "城市", "标签"})
+	birthday := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		axis, _ := excelize.CoordinatesToCellName(1, i+2)
+		_ = sw.SetRow(axis, []interface{}{i, fmt.Sprintf("姓名%d", i), i % 100, float64(i) / 3, i%2 == 0,
+			birthday.AddDate(0, 0, i), "江苏", "南京", "甲,乙,丙"})
+	}
+	if err = sw.Flush(); err != nil {
+		b.Fatal(err)
+	}
+	return f
+}
+
+func BenchmarkReadFromFile(b *testing.B) {
+	f := newBenchFile(b, 1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ReadFromFile[benchRow](f, "Sheet1"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// a single iterator reads the rows of the text or the raw values, the mixed rows read both
+func BenchmarkReadViews(b *testing.B) {
+	type textRow struct {
+		Name string `x-read:"姓名"`
+		Tags string `x-read:"标签"`
+	}
+	type rawRow struct {
+		Id    int     `x-read:"编号"`
+		Score float64 `x-read:"成绩"`
+	}
+	type mixedRow struct {
+		Name  string  `x-read:"姓名"`
+		Score float64 `x-read:"成绩"`
+	}
+	f := newBenchFile(b, 1000)
+	for name, t := range map[string]reflect.Type{
+		"text":  reflect.TypeOf(textRow{}),
+		"raw":   reflect.TypeOf(rawRow{}),
+		"mixed": reflect.TypeOf(mixedRow{}),
+	} {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := readSheet(context.Background(), f, "Sheet1", t, newConfig(nil)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkReader(b *testing.B) {
+	f := newBenchFile(b, 1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r, err := NewReader[benchRow](f, "Sheet1")
+		if err != nil {
+			b.Fatal(err)
+		}
+		var row benchRow
+		for r.Next() {
+			if err = r.Scan(&row); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err = r.Close(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// the small sheets are dominated by building the field mapping
+func BenchmarkReadSmallSheet(b *testing.B) {
+	f := newBenchFile(b, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ReadFromFile[benchRow](f, "Sheet1"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteToSheet(b *testing.B) {
+	rows := make([]benchRow, 1000)
+	for i := range rows {
+		rows[i] = benchRow{Id: i, Name: fmt.Sprintf("姓名%d", i), Score: float64(i) / 3, Tags: []string{"甲", "乙"}}
+	}
+	path := filepath.Join(b.TempDir(), "bench.xlsx")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := WriteToSheet(path, "Sheet1", rows); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// the mapping of the fields to the header, compiled for every call or taken from the cached schema
+func BenchmarkFieldMapping(b *testing.B) {
+	header := []string{"编号", "姓名", "年龄", "成绩", "通过", "生日", "省份", "城市", "标签"}
+	t := reflect.TypeOf(benchRow{})
+	cfg := newConfig(nil)
+	b.Run("compiled", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s, err := compileSchema(t)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err = initFieldMapping(header, s, cfg); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s, err := schemaOf(t)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err = initFieldMapping(header, s, cfg); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// the decoding of a row without the parsing of the worksheet
+func BenchmarkDecodeRow(b *testing.B) {
+	f := newBenchFile(b, 1)
+	sr, err := newSheetReader(context.Background(), f, "Sheet1", reflect.TypeOf(benchRow{}), newConfig(nil))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer sr.close()
+	if !sr.next() {
+		b.Fatal("no data row")
+	}
+	var row benchRow
+	v := reflect.ValueOf(&row).Elem()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if errs := sr.setDataForObject(v); len(errs) > 0 {
+			b.Fatal(errs[0])
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package excel
+
+import (
+	"sync"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Cell is a cell of the sheet passed to the CellUnmarshaler.
+type Cell struct {
+	// the sheet name.
+	Sheet string
+	// the row number and column number of the cell, start from 1.
+	Row, Col int
+	// the raw value stored in the cell, the number format is not applied, such as 45300 for a date.
+	// The string fields are decoded from the Text instead.
+	Value string
+	// the text of the cell as displayed by the number format, such as 1/9/24 00:00.
+	// It is read for the CellUnmarshaler, but it may be empty for the other decoders.
+	Text string
+	// whether the workbook uses the 1904 date system, used to convert the serial number of a date.
+	Date1904 bool
+	// the workbook the cell belongs to, and the lock held when looking up the workbook.
+	file *excelize.File
+	mu   *sync.Mutex
+}
+
+// The A1 reference of the cell, such as F3
+func (c Cell) Axis() string {
+	axis, _ := excelize.CoordinatesToCellName(c.Col, c.Row)
+	return axis
+}
+
+// The type of the cell. It is looked up in the workbook on demand,
+// the first call loads the whole worksheet in memory
+func (c Cell) Type() (excelize.CellType, error) {
+	if c.file == nil {
+		return excelize.CellTypeUnset, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.file.GetCellType(c.Sheet, c.Axis())
+}
+
+// The number format of the cell, numFmt is the built-in number format id and customNumFmt is the custom format code if any.
+// It is looked up in the workbook on demand, the first call loads the whole worksheet in memory
+func (c Cell) NumFmt() (numFmt int, customNumFmt string, err error) {
+	if c.file == nil {
+		return 0, "", nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	styleID, err := c.file.GetCellStyle(c.Sheet, c.Axis())
+	if err != nil {
+		return 0, "", err
+	}
+	style, err := c.file.GetStyle(styleID)
+	if err != nil {
+		return 0, "", err
+	}
+	if style.CustomNumFmt != nil {
+		customNumFmt = *style.CustomNumFmt
+	}
+	return style.NumFmt, customNumFmt, nil
+}
+
+// CellUnmarshaler is implemented by the field types that decode themselves from a cell.
+// It takes precedence over encoding.TextUnmarshaler and the built-in conversions.
+type CellUnmarshaler interface {
+	UnmarshalCell(cell Cell) error
+}
+
+// CellMarshaler is implemented by the field types that encode themselves to a cell.
+// The returned value is written as the stream writer of excelize writes it, such as
+// a string, a number, a bool or a time.Time, and nil leaves the cell empty.
+// It takes precedence over encoding.TextMarshaler and the built-in conversions.
+type CellMarshaler interface {
+	MarshalCell() (interface{}, error)
+}
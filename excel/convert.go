@@ -0,0 +1,318 @@
+package excel
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	dateType            = reflect.TypeOf(Date{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	cellUnmarshalerType = reflect.TypeOf((*CellUnmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// The views of the cell read by the field. The strings read the formatted text, the bools read both to tell
+// a boolean cell, the fields decoding themselves read both for Cell.Text, and the others read the raw value.
+func cellViews(item *FieldMappingItem) (text, raw bool) {
+	if item.converter != nil {
+		return false, true
+	}
+	t := item.valueType()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	pt := reflect.PointerTo(t)
+	switch {
+	case pt.Implements(cellUnmarshalerType):
+		return true, true
+	case pt.Implements(textUnmarshalerType):
+		return false, true
+	case t.Kind() == reflect.String:
+		return true, false
+	case t.Kind() == reflect.Bool:
+		return true, true
+	}
+	return false, true
+}
+
+// decode the cell to the field, the registered converter is used first, then the field types
+// implementing CellUnmarshaler or encoding.TextUnmarshaler decode themselves, the others are converted by the kind.
+// A pointer field is nil when the cell is empty, otherwise the value it points to is decoded.
+func decodeCell(field reflect.Value, cell Cell, item *FieldMappingItem, cfg *config) error {
+	conv := item.converter
+	if conv != nil && conv.decode != nil && conv.typ == field.Type() {
+		value, err := conv.decode(cell.Value)
+		if err != nil {
+			return err
+		}
+		field.Set(value)
+		return nil
+	}
+	if field.CanAddr() {
+		addr := field.Addr()
+		if addr.Type().Implements(cellUnmarshalerType) {
+			return addr.Interface().(CellUnmarshaler).UnmarshalCell(cell)
+		}
+		// time.Time is a TextUnmarshaler, but it is stored as a serial number in the cell
+		if field.Type() != timeType && addr.Type().Implements(textUnmarshalerType) {
+			return addr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(strings.TrimSpace(cell.Value)))
+		}
+	}
+	switch field.Type() {
+	case timeType, dateType, durationType:
+		return set2Time(field, cell, item, cfg)
+	}
+	switch field.Type().Kind() {
+	case reflect.String:
+		if cfg.keepSpace {
+			field.SetString(cell.Value)
+		} else {
+			field.SetString(strings.TrimSpace(cell.Value))
+		}
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return set2Int(field, cfg.numberText(cell))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return set2Uint(field, cfg.numberText(cell))
+	case reflect.Float32, reflect.Float64:
+		return set2Float(field, cfg.numberText(cell))
+	case reflect.Bool:
+		return set2bool(field, cell, cfg)
+	case reflect.Pointer:
+		if strings.TrimSpace(cell.Value) == "" {
+			field.SetZero()
+			return nil
+		}
+		elem := reflect.New(field.Type().Elem())
+		if err := decodeCell(elem.Elem(), cell, item, cfg); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	case reflect.Complex64, reflect.Complex128:
+		return fmt.Errorf("the complex type %s can't be read from a cell", field.Type().String())
+	}
+	return fmt.Errorf("the type %s can't be read from a cell", field.Type().String())
+}
+
+// Decode the cell holding several values separated by the sep to the slice field, such as 南京,苏州,无锡.
+// Every value is trimmed and decoded as a cell, the empty values are left out.
+func decodeSplitCell(field reflect.Value, cell Cell, sep string, item *FieldMappingItem, cfg *config) error {
+	parts := strings.Split(cell.Value, sep)
+	values := reflect.MakeSlice(field.Type(), 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		value := reflect.New(field.Type().Elem()).Elem()
+		cell.Value = part
+		if err := decodeCell(value, cell, item, cfg); err != nil {
+			return err
+		}
+		values = reflect.Append(values, value)
+	}
+	field.Set(values)
+	return nil
+}
+
+// set the cell value to an integer field, the value out of the range of the field type is an error.
+// A number stored as a float such as 1.2E+3 is accepted if it is a whole number.
+func set2Int(value reflect.Value, str string) error {
+	str = strings.TrimSpace(str)
+	bitSize := value.Type().Bits()
+	intValue, err := strconv.ParseInt(str, 10, bitSize)
+	if errors.Is(err, strconv.ErrSyntax) {
+		intValue, err = parseWholeNumber(str, bitSize, true)
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("the value=%s overflows the type %s", str, value.Type().String())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to convert value=%s to a int", str)
+	}
+	value.SetInt(intValue)
+	return nil
+}
+
+// set the cell value to an unsigned integer field, the negative value or the value out of the range is an error
+func set2Uint(value reflect.Value, str string) error {
+	str = strings.TrimSpace(str)
+	bitSize := value.Type().Bits()
+	uintValue, err := strconv.ParseUint(str, 10, bitSize)
+	if errors.Is(err, strconv.ErrSyntax) {
+		var intValue int64
+		intValue, err = parseWholeNumber(str, bitSize, false)
+		uintValue = uint64(intValue)
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("the value=%s overflows the type %s", str, value.Type().String())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to convert value=%s to a uint", str)
+	}
+	value.SetUint(uintValue)
+	return nil
+}
+
+// parse the whole number written as a float, such as 1.2E+3, the fraction is a syntax error
+func parseWholeNumber(str string, bitSize int, signed bool) (int64, error) {
+	floatValue, err := strconv.ParseFloat(str, 64)
+	if err != nil || floatValue != math.Trunc(floatValue) {
+		return 0, strconv.ErrSyntax
+	}
+	minValue, maxValue := 0.0, math.Ldexp(1, bitSize)
+	if signed {
+		minValue, maxValue = -math.Ldexp(1, bitSize-1), math.Ldexp(1, bitSize-1)
+	}
+	// the unsigned 64-bit numbers above math.MaxInt64 are beyond the float precision anyway
+	if floatValue < minValue || floatValue >= maxValue || floatValue >= math.Ldexp(1, 63) {
+		return 0, strconv.ErrRange
+	}
+	return int64(floatValue), nil
+}
+
+// set the cell value to a float field, the value is rounded to the precision of float32 for a float32 field
+func set2Float(value reflect.Value, str string) error {
+	str = strings.TrimSpace(str)
+	floatValue, err := strconv.ParseFloat(str, value.Type().Bits())
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("the value=%s overflows the type %s", str, value.Type().String())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to convert value=%s to a float", str)
+	}
+	value.SetFloat(floatValue)
+	return nil
+}
+
+// the layouts of the dates stored as text, the one-digit month and day are accepted as well
+var dateLayouts = []string{
+	"2006-1-2",
+	"2006-1-2 15:04",
+	"2006-1-2 15:04:05",
+	"2006/1/2",
+	"2006/1/2 15:04",
+	"2006/1/2 15:04:05",
+	"2006.1.2",
+	"2006年1月2日",
+	time.RFC3339,
+}
+
+// Set the cell value to a time.Time, Date or time.Duration field. The value is the serial number of the date
+// or the date as text, parsed by the layout option of the tag or the dateLayouts, in the location of WithLocation.
+// A duration is the fraction of the days such as 0.5 for 12:00, or the text like 1:30:00 or 1h30m.
+func set2Time(value reflect.Value, cell Cell, item *FieldMappingItem, cfg *config) error {
+	str := strings.TrimSpace(cell.Value)
+	layout, _ := item.tag.option(layoutOption)
+	if value.Type() == durationType {
+		duration, err := parseDuration(str)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(duration))
+		return nil
+	}
+	toTime, err := parseTime(str, layout, cell.Date1904, cfg.timeLocation())
+	if err != nil {
+		return err
+	}
+	if value.Type() == dateType {
+		value.Set(reflect.ValueOf(DateOf(toTime)))
+		return nil
+	}
+	value.Set(reflect.ValueOf(toTime))
+	return nil
+}
+
+// parse the serial number of the date, or the date as text with the layout if any, otherwise with the dateLayouts.
+// The serial number has no time zone, it is the clock in the location.
+func parseTime(str string, layout string, date1904 bool, loc *time.Location) (time.Time, error) {
+	if layout != "" {
+		if toTime, err := time.ParseInLocation(layout, str, loc); err == nil {
+			return toTime, nil
+		}
+	}
+	if floatValue, err := strconv.ParseFloat(str, 64); err == nil {
+		toTime, err := excelize.ExcelDateToTime(floatValue, date1904)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to convert value=%s to a time", str)
+		}
+		return time.Date(toTime.Year(), toTime.Month(), toTime.Day(),
+			toTime.Hour(), toTime.Minute(), toTime.Second(), toTime.Nanosecond(), loc), nil
+	}
+	if layout != "" {
+		return time.Time{}, fmt.Errorf("failed to convert value=%s to a time by the layout %s", str, layout)
+	}
+	for _, layout := range dateLayouts {
+		if toTime, err := time.ParseInLocation(layout, str, loc); err == nil {
+			return toTime, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("failed to convert value=%s to a time", str)
+}
+
+// parse the fraction of the days, the clock like 1:30 or 1:30:00.5, or the Go duration like 1h30m.
+// The fraction is rounded to the millisecond, the precision of Excel.
+func parseDuration(str string) (time.Duration, error) {
+	if days, err := strconv.ParseFloat(str, 64); err == nil {
+		return time.Duration(math.Round(days*24*60*60*1000)) * time.Millisecond, nil
+	}
+	if duration, err := time.ParseDuration(str); err == nil {
+		return duration, nil
+	}
+	parts := strings.Split(str, ":")
+	if len(parts) == 2 || len(parts) == 3 {
+		hours, errH := strconv.ParseUint(parts[0], 10, 32)
+		minutes, errM := strconv.ParseUint(parts[1], 10, 8)
+		seconds := 0.0
+		var errS error
+		if len(parts) == 3 {
+			seconds, errS = strconv.ParseFloat(parts[2], 64)
+		}
+		if errH == nil && errM == nil && errS == nil && minutes < 60 && seconds >= 0 && seconds < 60 {
+			return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute +
+				time.Duration(math.Round(seconds*1000))*time.Millisecond, nil
+		}
+	}
+	return 0, fmt.Errorf("failed to convert value=%s to a duration", str)
+}
+
+// the value of a boolean cell of Excel, it is stored as 1 or 0 and displayed as TRUE or FALSE,
+// so the type of the cell is told without looking it up in the workbook
+func boolCellValue(cell Cell) (value bool, ok bool) {
+	switch {
+	case cell.Value == "1" && cell.Text == "TRUE":
+		return true, true
+	case cell.Value == "0" && cell.Text == "FALSE":
+		return false, true
+	}
+	return false, false
+}
+
+// set the cell value to a bool field by the vocabulary of WithBoolValues, an empty cell is false
+func set2bool(value reflect.Value, cell Cell, cfg *config) error {
+	str := strings.TrimSpace(cell.Value)
+	if str == "" {
+		value.SetBool(false)
+		return nil
+	}
+	v, ok := boolCellValue(cell)
+	if !ok {
+		v, ok = cfg.boolValue(str)
+	}
+	if !ok {
+		return fmt.Errorf("failed to convert value=%s to a bool", str)
+	}
+	value.SetBool(v)
+	return nil
+}
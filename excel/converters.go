@@ -0,0 +1,131 @@
+package excel
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Converters is a registry of the conversions between the cells and the types,
+// such as the third-party types that can't implement CellUnmarshaler and CellMarshaler.
+// It is passed to a call by WithConverters, register the conversions before the call.
+//
+//	conv := excel.NewConverters()
+//	excel.RegisterConverter(conv, time.ParseDuration)
+//	excel.RegisterNamedConverter(conv, "cents", func(raw string) (int64, error) { ... })
+//	rows, err := excel.ReadFromSheet[Order]("orders.xlsx", "订单", excel.WithConverters(conv))
+//
+// A conversion registered by name is used by the fields tagged with the conv option,
+// such as x-read:"金额;conv=cents", it takes precedence over the conversion registered by type.
+type Converters struct {
+	byType map[reflect.Type]*converter
+	byName map[string]*converter
+}
+
+// converter is the conversion of a type, the decode or the encode is nil if it is not registered
+type converter struct {
+	typ    reflect.Type
+	decode func(raw string) (reflect.Value, error)
+	encode func(value reflect.Value) (interface{}, error)
+}
+
+// Create an empty registry of the conversions
+func NewConverters() *Converters {
+	return &Converters{
+		byType: make(map[reflect.Type]*converter),
+		byName: make(map[string]*converter),
+	}
+}
+
+// Register the decoding of the raw cell value to the type T, it is used for every field of the type T
+func RegisterConverter[T any](c *Converters, decode func(raw string) (T, error)) {
+	conv := c.forType(reflect.TypeOf((*T)(nil)).Elem())
+	conv.decode = wrapDecode(decode)
+}
+
+// Register the decoding of the raw cell value to the type T by name, it is used for the fields tagged with conv=name
+func RegisterNamedConverter[T any](c *Converters, name string, decode func(raw string) (T, error)) {
+	conv := c.forName(name, reflect.TypeOf((*T)(nil)).Elem())
+	conv.decode = wrapDecode(decode)
+}
+
+// Register the encoding of the type T to a cell value, it is used for every field of the type T
+func RegisterEncoder[T any](c *Converters, encode func(value T) (interface{}, error)) {
+	conv := c.forType(reflect.TypeOf((*T)(nil)).Elem())
+	conv.encode = wrapEncode(encode)
+}
+
+// Register the encoding of the type T to a cell value by name, it is used for the fields tagged with conv=name
+func RegisterNamedEncoder[T any](c *Converters, name string, encode func(value T) (interface{}, error)) {
+	conv := c.forName(name, reflect.TypeOf((*T)(nil)).Elem())
+	conv.encode = wrapEncode(encode)
+}
+
+// the converter of the type, it is created if not exists
+func (c *Converters) forType(t reflect.Type) *converter {
+	conv, ok := c.byType[t]
+	if !ok {
+		conv = &converter{typ: t}
+		c.byType[t] = conv
+	}
+	return conv
+}
+
+// the converter of the name, it is replaced if the type of the registered one is different
+func (c *Converters) forName(name string, t reflect.Type) *converter {
+	conv, ok := c.byName[name]
+	if !ok || conv.typ != t {
+		conv = &converter{typ: t}
+		c.byName[name] = conv
+	}
+	return conv
+}
+
+// find the converter of the field, the one named by the conv option takes precedence over the one of the field type.
+// The converter of the type T is used for a field of the type *T as well, and for the elements of a field collecting columns.
+func (c *Converters) lookup(item *FieldMappingItem) (*converter, error) {
+	valueType := item.valueType()
+	if item.tag != nil {
+		if name, ok := item.tag.option(convOption); ok {
+			if c == nil || c.byName[name] == nil {
+				return nil, fmt.Errorf("the converter=%s of the field=%s is not registered", name, item.FieldName)
+			}
+			conv := c.byName[name]
+			if conv.typ != valueType && !isPointerTo(valueType, conv.typ) {
+				return nil, fmt.Errorf("the converter=%s converts the type %s, but the type of the field=%s is %s",
+					name, conv.typ.String(), item.FieldName, valueType.String())
+			}
+			return conv, nil
+		}
+	}
+	if c == nil {
+		return nil, nil
+	}
+	if conv, ok := c.byType[valueType]; ok {
+		return conv, nil
+	}
+	if valueType.Kind() == reflect.Pointer {
+		return c.byType[valueType.Elem()], nil
+	}
+	return nil, nil
+}
+
+// whether the type t is a pointer to the type elem
+func isPointerTo(t reflect.Type, elem reflect.Type) bool {
+	return t.Kind() == reflect.Pointer && t.Elem() == elem
+}
+
+func wrapDecode[T any](decode func(raw string) (T, error)) func(raw string) (reflect.Value, error) {
+	return func(raw string) (reflect.Value, error) {
+		value, err := decode(raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(&value).Elem(), nil
+	}
+}
+
+func wrapEncode[T any](encode func(value T) (interface{}, error)) func(value reflect.Value) (interface{}, error) {
+	return func(value reflect.Value) (interface{}, error) {
+		return encode(value.Interface().(T))
+	}
+}
@@ -0,0 +1,31 @@
+package excel
+
+import "time"
+
+// Date is a civil date without the clock and the time zone, such as a birthday or a due date.
+// It is read from a date cell or a date as text, and written as a date cell without the clock.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// the date of the time in its location
+func DateOf(t time.Time) Date {
+	return Date{Year: t.Year(), Month: t.Month(), Day: t.Day()}
+}
+
+// the midnight of the date in the location
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// whether the date is the zero value
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// the date as 2006-01-02
+func (d Date) String() string {
+	return d.In(time.UTC).Format(time.DateOnly)
+}
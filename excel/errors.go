@@ -0,0 +1,111 @@
+package excel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The errors of the limits set by the options, they are wrapped with the details, test them by errors.Is.
+var (
+	// the sheet has more data rows than WithMaxRows.
+	ErrTooManyRows = errors.New("excel: too many rows")
+	// a row has more columns than WithMaxColumns.
+	ErrTooManyColumns = errors.New("excel: too many columns")
+	// a cell is longer than WithMaxCellLength.
+	ErrCellTooLong = errors.New("excel: cell too long")
+	// the uncompressed workbook is larger than WithMaxSize.
+	ErrFileTooLarge = errors.New("excel: file too large")
+)
+
+// The errors of reading a sheet, they are wrapped with the details, test them by errors.Is.
+var (
+	// the sheet selected by the name, the index or the pattern doesn't exist.
+	ErrSheetNotFound = errors.New("excel: sheet not found")
+	// the sheet ends without any data row, such as a sheet with the header only, or the header row is empty.
+	// The sheet with the rows cut off by WithDataRows or WithStopMarker is not an empty sheet.
+	ErrEmptySheet = errors.New("excel: empty sheet")
+	// two columns of the header have the same name.
+	ErrDuplicateHeader = errors.New("excel: duplicate header")
+	// no column of the header is mapped to a required field, or no header is detected.
+	ErrMissingColumn = errors.New("excel: missing column")
+	// a column of the header is not mapped to any field with WithStrict.
+	ErrUnmappedColumn = errors.New("excel: unmapped column")
+	// a cell can't be converted to the type of the field, it is the cause of a CellError.
+	ErrConversion = errors.New("excel: conversion failed")
+)
+
+// HeaderError is the error of mapping the fields of the struct to the columns of the header.
+type HeaderError struct {
+	// the sheet name.
+	Sheet string
+	// the header of the column, empty if the error is about a field.
+	Column string
+	// the field name of the struct, empty if the error is about a column.
+	Field string
+	// the cause of the error, such as ErrDuplicateHeader, ErrMissingColumn or ErrUnmappedColumn.
+	Err error
+}
+
+func (e *HeaderError) Error() string {
+	msg := "sheet=" + e.Sheet
+	if e.Field != "" {
+		msg += ", field=" + e.Field
+	}
+	if e.Column != "" {
+		msg += ", col=" + e.Column
+	}
+	return msg + ", " + e.Err.Error()
+}
+
+func (e *HeaderError) Unwrap() error {
+	return e.Err
+}
+
+// CellError is the error of decoding a cell to a field of the struct.
+type CellError struct {
+	// the sheet name.
+	Sheet string
+	// the A1 reference of the cell, such as F3.
+	Cell string
+	// the row number of the cell, starts from 1.
+	Row int
+	// the header of the column.
+	Column string
+	// the field name of the struct.
+	Field string
+	// the value of the cell decoded to the field, the formatted text for the string fields.
+	Value string
+	// the cause of the error.
+	Err error
+}
+
+func (e *CellError) Error() string {
+	return fmt.Sprintf("sheet=%s, cell=%s, col=%s, %s", e.Sheet, e.Cell, e.Column, e.Err.Error())
+}
+
+func (e *CellError) Unwrap() error {
+	return e.Err
+}
+
+// RowErrors is the list of the cells that failed to decode, in the order of the rows and columns.
+type RowErrors []*CellError
+
+func (e RowErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	msgs := make([]string, len(e))
+	for i, cellErr := range e {
+		msgs[i] = cellErr.Error()
+	}
+	return fmt.Sprintf("%d cell(s) failed to decode: %s", len(e), strings.Join(msgs, "; "))
+}
+
+func (e RowErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, cellErr := range e {
+		errs[i] = cellErr
+	}
+	return errs
+}
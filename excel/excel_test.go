@@ -1,7 +1,24 @@
 package excel
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp/syntax"
+	"strconv"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/xuri/excelize/v2"
 )
 
 type JSProvience struct {
@@ -17,6 +34,1279 @@ type JSProvience struct {
 	Vender     string `x-read:"厂家"`
 }
 
+type City struct {
+	Id         int       `x-read:"序号,编号"`
+	City       string    `x-read:"城市"`
+	Code       int64     `x-read:"邮政编码,邮编" x-write:"邮编"`
+	Population float64   `x-read:"人口"`
+	Capital    bool      `x-read:"省会"`
+	Founded    time.Time `x-read:"日期"`
+}
+
 func TestRead(t *testing.T) {
 
 }
+
+func TestWriteToSheet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cities.xlsx")
+	cities := []City{
+		{Id: 1, City: "南京", Code: 210000, Population: 931.47, Capital: true, Founded: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)},
+		{Id: 2, City: "苏州", Code: 215000, Population: 1291.1, Capital: false, Founded: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)},
+	}
+	if err := WriteToSheet(path, "城市", cities); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadFromSheet[City](path, "城市")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, cities) {
+		t.Errorf("got %v, want %v", got, cities)
+	}
+}
+
+type Province struct {
+	Name    string `x-read:"省份"`
+	Capital string `x-read:"省会"`
+}
+
+func TestReadWorkbook(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workbook.xlsx")
+	cities := []City{{Id: 1, City: "南京", Code: 210000, Founded: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)}}
+	provinces := []Province{{Name: "江苏", Capital: "南京"}, {Name: "浙江", Capital: "杭州"}}
+	if err := WriteToSheet(path, "城市", cities); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteToSheet(path, "省份2024", provinces); err != nil {
+		t.Fatal(err)
+	}
+
+	type workbook struct {
+		Cities      []City     `x-sheet:"城市"`
+		FirstSheet  []City     `x-sheet:"[]"`
+		ByIndex     []Province `x-sheet:"[1]"`
+		ByPattern   []Province `x-sheet:"re:^省份\\d+$"`
+		Missing     []Province `x-sheet:"区县"`
+		OutOfRange  []Province `x-sheet:"[5]"`
+		NotATable   []Province `x-sheet:"-"`
+		notExported []Province
+	}
+	wb, err := ReadWorkbook[workbook](path)
+	var report *WorkbookError
+	if !errors.As(err, &report) {
+		t.Fatalf("expected a *WorkbookError, got %v", err)
+	}
+	if len(report.Sheets) != 2 || report.Sheets[0].FieldName != "Missing" || report.Sheets[1].FieldName != "OutOfRange" {
+		t.Errorf("unexpected report %v", report)
+	}
+	if !reflect.DeepEqual(wb.Cities, cities) || !reflect.DeepEqual(wb.FirstSheet, cities) {
+		t.Errorf("got %v, want %v", wb.Cities, cities)
+	}
+	if !reflect.DeepEqual(wb.ByIndex, provinces) || !reflect.DeepEqual(wb.ByPattern, provinces) {
+		t.Errorf("got %v and %v, want %v", wb.ByIndex, wb.ByPattern, provinces)
+	}
+	if wb.Missing != nil || wb.NotATable != nil || wb.notExported != nil {
+		t.Errorf("the skipped fields should be nil")
+	}
+}
+
+func TestReadWorkbookConcurrency(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workbook.xlsx")
+	var cities []City
+	for i := 1; i <= 200; i++ {
+		cities = append(cities, City{Id: i, City: "城市" + strconv.Itoa(i), Code: int64(210000 + i), Founded: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)})
+	}
+	provinces := []Province{{Name: "江苏", Capital: "南京"}, {Name: "浙江", Capital: "杭州"}}
+	for _, name := range []string{"一", "二", "三", "四"} {
+		if err := WriteToSheet(path, name, cities); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := WriteToSheet(path, "省份", provinces); err != nil {
+		t.Fatal(err)
+	}
+
+	type workbook struct {
+		First     []City     `x-sheet:"一"`
+		Invalid   []City     `x-sheet:"省份"`
+		Second    []City     `x-sheet:"二"`
+		Missing   []City     `x-sheet:"五"`
+		Third     []City     `x-sheet:"三"`
+		Fourth    []City     `x-sheet:"四"`
+		Provinces []Province `x-sheet:"省份"`
+	}
+	want, wantErr := ReadWorkbook[workbook](path)
+	for _, n := range []int{2, 3, 10} {
+		wb, err := ReadWorkbook[workbook](path, WithConcurrency(n))
+		if !reflect.DeepEqual(wb, want) {
+			t.Errorf("concurrency=%d, the sheets differ from the sequential reading", n)
+		}
+		var report *WorkbookError
+		if !errors.As(err, &report) || err.Error() != wantErr.Error() {
+			t.Fatalf("concurrency=%d, got %v, want %v", n, err, wantErr)
+		}
+		if len(report.Sheets) != 2 || report.Sheets[0].FieldName != "Invalid" || report.Sheets[1].FieldName != "Missing" {
+			t.Errorf("concurrency=%d, unexpected report %v", n, report)
+		}
+	}
+	if !reflect.DeepEqual(want.Fourth, cities) || !reflect.DeepEqual(want.Provinces, provinces) {
+		t.Errorf("got %v, want %v", want.Provinces, provinces)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ReadWorkbookContext[workbook](ctx, path, WithConcurrency(2)); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
+
+// numFmtCell records the number format of the cell, it is looked up in the workbook
+type numFmtCell int
+
+func (n *numFmtCell) UnmarshalCell(cell Cell) error {
+	numFmt, _, err := cell.NumFmt()
+	*n = numFmtCell(numFmt)
+	return err
+}
+
+func TestReadWorkbookConcurrencyLookups(t *testing.T) {
+	f := excelize.NewFile()
+	sheets := []string{"一", "二", "三", "四"}
+	for _, name := range sheets {
+		if _, err := f.NewSheet(name); err != nil {
+			t.Fatal(err)
+		}
+		_ = f.SetSheetRow(name, "A1", &[]interface{}{"地区", "人口", "", "格式"})
+		_ = f.SetSheetRow(name, "A2", &[]interface{}{"", "男", "女", ""})
+		_ = f.MergeCell(name, "A1", "A2")
+		_ = f.MergeCell(name, "B1", "C1")
+		for i := 3; i < 100; i++ {
+			_ = f.SetSheetRow(name, fmt.Sprintf("A%d", i), &[]interface{}{name, "1.234,5", 451.5, 0})
+		}
+	}
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	type census struct {
+		Region string     `x-read:"地区"`
+		Male   float64    `x-read:"人口/男"`
+		Female float64    `x-read:"人口/女"`
+		NumFmt numFmtCell `x-read:"格式"`
+	}
+	type workbook struct {
+		First  []census `x-sheet:"一"`
+		Second []census `x-sheet:"二"`
+		Third  []census `x-sheet:"三"`
+		Fourth []census `x-sheet:"四"`
+	}
+	// the merged cells, the types and the number formats of the cells are looked up in the shared workbook
+	wb, err := ReadWorkbookFromReader[workbook](bytes.NewReader(buf.Bytes()), WithConcurrency(4),
+		WithLocale(Locale{DecimalSeparator: ',', GroupSeparator: '.'}), WithHeaderRows(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, rows := range [][]census{wb.First, wb.Second, wb.Third, wb.Fourth} {
+		want := census{Region: sheets[i], Male: 1234.5, Female: 451.5}
+		if len(rows) != 97 || rows[0] != want || rows[96] != want {
+			t.Errorf("sheet %s: got %d rows, want %v", sheets[i], len(rows), want)
+		}
+	}
+}
+
+func TestReadFromSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cities.xlsx")
+	cities := []City{{Id: 1, City: "南京", Code: 210000, Founded: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)}}
+	if err := WriteToSheet(path, "城市", cities); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	sources := map[string]func() ([]City, error){
+		"reader": func() ([]City, error) { return ReadFromReader[City](bytes.NewReader(data), "城市") },
+		"bytes":  func() ([]City, error) { return ReadFromBytes[City](data, "城市") },
+		"fs": func() ([]City, error) {
+			return ReadFromFS[City](fstest.MapFS{"data/cities.xlsx": {Data: data}}, "data/cities.xlsx", "城市")
+		},
+		"file": func() ([]City, error) { return ReadFromFile[City](f, "城市") },
+	}
+	for name, read := range sources {
+		got, err := read()
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if !reflect.DeepEqual(got, cities) {
+			t.Errorf("%s: got %v, want %v", name, got, cities)
+		}
+	}
+}
+
+func TestReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cities.xlsx")
+	cities := make([]City, 100)
+	for i := range cities {
+		cities[i] = City{Id: i + 1, City: "城市", Founded: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)}
+	}
+	if err := WriteToSheet(path, "城市", cities); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenSheet[City](path, "城市")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	count := 0
+	for r.Next() {
+		var c City
+		if err := r.Scan(&c); err != nil {
+			t.Fatal(err)
+		}
+		if c.Id != count+1 || r.RowNumber() != count+2 {
+			t.Errorf("row %d decoded as %v", r.RowNumber(), c)
+		}
+		count++
+		if count == 10 {
+			break
+		}
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if count != 10 {
+		t.Errorf("got %d rows, want 10", count)
+	}
+
+	// a sheet with the header only has no data
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetRow("Sheet1", "A1", &[]any{"序号", "城市", "邮编", "人口", "省会", "日期"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadFromFile[City](f, "Sheet1"); !errors.Is(err, ErrEmptySheet) {
+		t.Errorf("expected ErrEmptySheet, got %v", err)
+	}
+	empty, err := NewReader[City](f, "Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer empty.Close()
+	if empty.Next() || !errors.Is(empty.Err(), ErrEmptySheet) {
+		t.Errorf("expected ErrEmptySheet, got %v", empty.Err())
+	}
+
+	// the data rows starting after the last row are cut off like the rows after the end row
+	_ = f.SetSheetRow("Sheet1", "A2", &[]any{1, "南京"})
+	if got, err := ReadFromFile[City](f, "Sheet1", WithDataRows(10, 0)); err != nil || len(got) != 0 {
+		t.Errorf("got %v and %v, want no rows and no error", got, err)
+	}
+
+	// the fields not decoded keep no value of the last row when dest is reused
+	type person struct {
+		Name string `x-read:"名"`
+		Age  int    `x-read:"龄;omitempty"`
+		Note string `x-read:"备注;optional"`
+	}
+	g := excelize.NewFile()
+	defer g.Close()
+	_ = g.SetSheetRow("Sheet1", "A1", &[]any{"名", "龄"})
+	_ = g.SetSheetRow("Sheet1", "A2", &[]any{"a", 30})
+	_ = g.SetSheetRow("Sheet1", "A3", &[]any{"b"})
+	pr, err := NewReader[person](g, "Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.Close()
+	var p person
+	var got []person
+	for pr.Next() {
+		p.Note = "stale"
+		if err := pr.Scan(&p); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, p)
+	}
+	if want := []person{{Name: "a", Age: 30}, {Name: "b"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestReadDates(t *testing.T) {
+	type event struct {
+		Name string    `x-read:"名称"`
+		At   time.Time `x-read:"日期"`
+	}
+	want := time.Date(2024, 1, 9, 8, 30, 0, 0, time.UTC)
+	for _, date1904 := range []bool{false, true} {
+		f := excelize.NewFile()
+		if err := f.SetWorkbookProps(&excelize.WorkbookPropsOptions{Date1904: &date1904}); err != nil {
+			t.Fatal(err)
+		}
+		_ = f.SetSheetRow("Sheet1", "A1", &[]interface{}{"名称", "日期"})
+		_ = f.SetSheetRow("Sheet1", "A2", &[]interface{}{"serial", want})
+		_ = f.SetSheetRow("Sheet1", "A3", &[]interface{}{"text", "2024/1/9 08:30"})
+		_ = f.SetSheetRow("Sheet1", "A4", &[]interface{}{"chinese", "2024年1月9日"})
+		styleBefore, _ := f.GetColStyle("Sheet1", "B")
+
+		got, err := ReadFromFile[event](f, "Sheet1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []event{{"serial", want}, {"text", want}, {"chinese", want.Truncate(24 * time.Hour)}}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("date1904=%v: got %v, want %v", date1904, got, expected)
+		}
+		if styleAfter, _ := f.GetColStyle("Sheet1", "B"); styleAfter != styleBefore {
+			t.Errorf("the style of the date column is modified from %d to %d", styleBefore, styleAfter)
+		}
+	}
+}
+
+func TestFormattedCells(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	percent, err := f.NewStyle(&excelize.Style{NumFmt: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dateTime, err := f.NewStyle(&excelize.Style{NumFmt: 22})
+	if err != nil {
+		t.Fatal(err)
+	}
+	month, err := f.NewStyle(&excelize.Style{NumFmt: 17})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for axis, value := range map[string]any{"A1": "比例", "B1": "比例文本", "C1": "日期", "D1": "日期文本", "E1": 45292.0,
+		"A2": 0.125, "B2": 0.125, "C2": 45300.0, "D2": 45300.0, "E2": 3} {
+		if err = f.SetCellValue("Sheet1", axis, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for axis, style := range map[string]int{"A2": percent, "B2": percent, "C2": dateTime, "D2": dateTime, "E1": month} {
+		if err = f.SetCellStyle("Sheet1", axis, axis, style); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type row struct {
+		Ratio     float64   `x-read:"比例"`
+		RatioText string    `x-read:"比例文本"`
+		Date      time.Time `x-read:"日期"`
+		DateText  string    `x-read:"日期文本"`
+		January   int       `x-read:"Jan-24"`
+	}
+	rows, err := ReadFromFile[row](f, "Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := row{Ratio: 0.125, RatioText: "12.50%", Date: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC), DateText: "1/9/24 00:00", January: 3}
+	if len(rows) != 1 || rows[0] != want {
+		t.Errorf("got %v, want %v", rows, want)
+	}
+
+	// a single iterator reads the data rows unless the fields read both the text and the raw values
+	type texts struct {
+		RatioText string `x-read:"比例文本"`
+		DateText  string `x-read:"日期文本"`
+	}
+	type numbers struct {
+		Ratio   float64 `x-read:"比例"`
+		January int     `x-read:"Jan-24"`
+	}
+	views := map[string]struct {
+		t         reflect.Type
+		text, raw bool
+	}{
+		"text":  {reflect.TypeOf(texts{}), true, false},
+		"raw":   {reflect.TypeOf(numbers{}), false, true},
+		"mixed": {reflect.TypeOf(row{}), true, true},
+	}
+	for name, v := range views {
+		sr, err := newSheetReader(context.Background(), f, "Sheet1", v.t, newConfig([]Option{WithConverters(NewConverters())}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (sr.rows != nil) != v.text || (sr.rawRows != nil) != v.raw {
+			t.Errorf("%s: got the text %v and the raw values %v", name, sr.rows != nil, sr.rawRows != nil)
+		}
+		sr.close()
+	}
+	numberRows, err := ReadFromFile[numbers](f, "Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(numberRows) != 1 || numberRows[0] != (numbers{Ratio: 0.125, January: 3}) {
+		t.Errorf("got %v", numberRows)
+	}
+}
+
+func TestCollectErrors(t *testing.T) {
+	type row struct {
+		Name  string  `x-read:"名称"`
+		Count int     `x-read:"数量"`
+		Price float64 `x-read:"价格"`
+	}
+	f := excelize.NewFile()
+	defer f.Close()
+	_ = f.SetSheetRow("Sheet1", "A1", &[]interface{}{"名称", "数量", "价格"})
+	_ = f.SetSheetRow("Sheet1", "A2", &[]interface{}{"a", 1, 1.5})
+	_ = f.SetSheetRow("Sheet1", "A3", &[]interface{}{"b", "x", "y"})
+	_ = f.SetSheetRow("Sheet1", "A4", &[]interface{}{"c", 3, 3.5})
+	_ = f.SetSheetRow("Sheet1", "A5", &[]interface{}{"d", "4.5", 4.5})
+
+	_, err := ReadFromFile[row](f, "Sheet1")
+	var cellErr *CellError
+	if !errors.As(err, &cellErr) || cellErr.Cell != "B3" {
+		t.Fatalf("expected the error of B3, got %v", err)
+	}
+
+	got, err := ReadFromFile[row](f, "Sheet1", WithCollectErrors())
+	if want := []row{{"a", 1, 1.5}, {"c", 3, 3.5}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	var rowErrs RowErrors
+	if !errors.As(err, &rowErrs) {
+		t.Fatalf("expected RowErrors, got %v", err)
+	}
+	want := []CellError{
+		{Sheet: "Sheet1", Cell: "B3", Row: 3, Column: "数量", Field: "Count", Value: "x"},
+		{Sheet: "Sheet1", Cell: "C3", Row: 3, Column: "价格", Field: "Price", Value: "y"},
+		{Sheet: "Sheet1", Cell: "B5", Row: 5, Column: "数量", Field: "Count", Value: "4.5"},
+	}
+	if len(rowErrs) != len(want) {
+		t.Fatalf("got %d errors, want %d: %v", len(rowErrs), len(want), rowErrs)
+	}
+	for i, e := range rowErrs {
+		w := want[i]
+		w.Err = e.Err
+		if *e != w {
+			t.Errorf("got %+v, want %+v", *e, w)
+		}
+	}
+}
+
+type level int
+
+func (l *level) UnmarshalCell(cell Cell) error {
+	for i, name := range []string{"低", "中", "高"} {
+		if cell.Value == name {
+			*l = level(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown level %s", cell.Value)
+}
+
+func (l level) MarshalCell() (interface{}, error) {
+	return []string{"低", "中", "高"}[l], nil
+}
+
+func TestCustomCellDecoding(t *testing.T) {
+	type server struct {
+		Name  string `x-read:"名称"`
+		IP    net.IP `x-read:"地址"`
+		Level level  `x-read:"级别"`
+	}
+	path := filepath.Join(t.TempDir(), "servers.xlsx")
+	servers := []server{{"a", net.ParseIP("10.0.0.1"), 2}, {"b", net.ParseIP("::1"), 0}}
+	if err := WriteToSheet(path, "Sheet1", servers); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadFromSheet[server](path, "Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, servers) {
+		t.Errorf("got %v, want %v", got, servers)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if v, _ := f.GetCellValue("Sheet1", "C2"); v != "高" {
+		t.Errorf("the level is written as %s", v)
+	}
+	_ = f.SetCellValue("Sheet1", "C3", "未知")
+	_, err = ReadFromFile[server](f, "Sheet1")
+	var cellErr *CellError
+	if !errors.As(err, &cellErr) || cellErr.Cell != "C3" {
+		t.Errorf("expected the error of C3, got %v", err)
+	}
+}
+
+func TestConverters(t *testing.T) {
+	type task struct {
+		Name     string        `x-read:"名称"`
+		Duration time.Duration `x-read:"用时"`
+		Budget   int64         `x-read:"预算;conv=cents"`
+	}
+	conv := NewConverters()
+	RegisterConverter(conv, time.ParseDuration)
+	RegisterEncoder(conv, func(d time.Duration) (interface{}, error) { return d.String(), nil })
+	RegisterNamedConverter(conv, "cents", func(raw string) (int64, error) {
+		yuan, err := strconv.ParseFloat(raw, 64)
+		return int64(math.Round(yuan * 100)), err
+	})
+	RegisterNamedEncoder(conv, "cents", func(cents int64) (interface{}, error) { return float64(cents) / 100, nil })
+
+	path := filepath.Join(t.TempDir(), "tasks.xlsx")
+	tasks := []task{{"a", 90 * time.Minute, 1250}, {"b", time.Second, 99}}
+	if err := WriteToSheet(path, "Sheet1", tasks, WithConverters(conv)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadFromSheet[task](path, "Sheet1", WithConverters(conv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, tasks) {
+		t.Errorf("got %v, want %v", got, tasks)
+	}
+	if _, err = ReadFromSheet[task](path, "Sheet1"); err == nil {
+		t.Error("expected an error for the converter not registered")
+	}
+}
+
+// a sheet with the title rows before the header and the total row after the data
+func newTemplateFile(t *testing.T) *excelize.File {
+	f := excelize.NewFile()
+	t.Cleanup(func() { f.Close() })
+	_ = f.SetCellValue("Sheet1", "A1", "江苏省城市基础信息表")
+	_ = f.MergeCell("Sheet1", "A1", "C1")
+	_ = f.SetCellValue("Sheet1", "A2", "填报单位：省统计局")
+	_ = f.SetCellValue("Sheet1", "A3", "说明：城市")
+	_ = f.SetSheetRow("Sheet1", "A5", &[]interface{}{"序号", "城市", "人口"})
+	_ = f.SetSheetRow("Sheet1", "A6", &[]interface{}{1, "南京", 931.47})
+	_ = f.SetSheetRow("Sheet1", "A7", &[]interface{}{2, "苏州", 1291.1})
+	_ = f.SetSheetRow("Sheet1", "A8", &[]interface{}{3, "无锡", 749.08})
+	_ = f.SetSheetRow("Sheet1", "A9", &[]interface{}{"合计", "", 2971.65})
+	_ = f.SetCellValue("Sheet1", "A10", "注：人口单位为万人")
+	return f
+}
+
+func TestHeaderRow(t *testing.T) {
+	type city struct {
+		Id         int     `x-read:"序号"`
+		City       string  `x-read:"城市"`
+		Population float64 `x-read:"人口"`
+	}
+	f := newTemplateFile(t)
+	all := []city{{1, "南京", 931.47}, {2, "苏州", 1291.1}, {3, "无锡", 749.08}}
+	cases := map[string]struct {
+		opts []Option
+		want []city
+	}{
+		"header row":       {[]Option{WithHeaderRow(5), WithStopMarker("合计")}, all},
+		"header detection": {[]Option{WithHeaderDetection(0), WithStopMarker(" 合计 ")}, all},
+		"data rows":        {[]Option{WithHeaderRow(5), WithDataRows(7, 8)}, all[1:]},
+	}
+	for name, c := range cases {
+		got, err := ReadFromFile[city](f, "Sheet1", c.opts...)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %v, want %v", name, got, c.want)
+		}
+	}
+	if _, err := ReadFromFile[city](f, "Sheet1", WithHeaderRow(4)); err == nil {
+		t.Error("expected an error for the empty header row")
+	}
+	var headerErr *HeaderError
+	_, err := ReadFromFile[city](f, "Sheet1", WithHeaderDetection(3))
+	if !errors.Is(err, ErrMissingColumn) || !errors.As(err, &headerErr) || headerErr.Sheet != "Sheet1" {
+		t.Errorf("expected ErrMissingColumn for the header out of the scanned rows, got %v", err)
+	}
+}
+
+func TestMultiRowHeader(t *testing.T) {
+	type census struct {
+		Region string `x-read:"地区"`
+		Male   int    `x-read:"人口/男"`
+		Female int    `x-read:"人口/女"`
+		Homes  int    `x-read:"户数/合计"`
+	}
+	f := excelize.NewFile()
+	defer f.Close()
+	_ = f.SetCellValue("Sheet1", "A1", "人口普查")
+	_ = f.SetSheetRow("Sheet1", "A2", &[]interface{}{"地区", "人口", "", "户数"})
+	_ = f.SetSheetRow("Sheet1", "A3", &[]interface{}{"", "男", "女", "合计"})
+	_ = f.MergeCell("Sheet1", "A2", "A3")
+	_ = f.MergeCell("Sheet1", "B2", "C2")
+	_ = f.SetSheetRow("Sheet1", "A4", &[]interface{}{"南京", 480, 451, 300})
+	_ = f.SetSheetRow("Sheet1", "A5", &[]interface{}{"苏州", 660, 631, 420})
+
+	want := []census{{"南京", 480, 451, 300}, {"苏州", 660, 631, 420}}
+	for _, opts := range [][]Option{
+		{WithHeaderRow(2), WithHeaderRows(2)},
+		{WithHeaderDetection(0), WithHeaderRows(2)},
+	} {
+		got, err := ReadFromFile[census](f, "Sheet1", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestEmptyCellOptions(t *testing.T) {
+	type contact struct {
+		Name  string  `x-read:"姓名;required"`
+		Phone string  `x-read:"电话;optional"`
+		Zone  int     `x-read:"电话区号;optional;default=25"`
+		Age   int     `x-read:"年龄;omitempty"`
+		Score float64 `x-read:"评分;default=60"`
+	}
+	f := excelize.NewFile()
+	defer f.Close()
+	_ = f.SetSheetRow("Sheet1", "A1", &[]interface{}{"姓名", "年龄", "评分"})
+	_ = f.SetSheetRow("Sheet1", "A2", &[]interface{}{"张三", 30, 90})
+	_ = f.SetSheetRow("Sheet1", "A3", &[]interface{}{"李四", nil, nil})
+	_ = f.SetSheetRow("Sheet1", "A4", &[]interface{}{nil, 20, 70})
+
+	got, err := ReadFromFile[contact](f, "Sheet1", WithCollectErrors())
+	want := []contact{{"张三", "", 25, 30, 90}, {"李四", "", 25, 0, 60}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	var rowErrs RowErrors
+	if !errors.As(err, &rowErrs) || len(rowErrs) != 1 || rowErrs[0].Cell != "A4" {
+		t.Errorf("expected the error of the required cell A4, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "contacts.xlsx")
+	if err = WriteToSheet(path, "Sheet1", want); err != nil {
+		t.Fatal(err)
+	}
+	written, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer written.Close()
+	if v, _ := written.GetCellValue("Sheet1", "D3"); v != "" {
+		t.Errorf("the zero value of an omitempty field is written as %q", v)
+	}
+}
+
+func TestPositionalColumns(t *testing.T) {
+	type vendorRow struct {
+		Code  string  `x-read:"col=A"`
+		Name  string  `x-read:"名称;idx=2"`
+		Price float64 `x-read:"col=D"`
+	}
+	f := excelize.NewFile()
+	defer f.Close()
+	_ = f.SetSheetRow("Sheet1", "A1", &[]interface{}{"V01", "ignored", "螺丝", 0.5})
+	_ = f.SetSheetRow("Sheet1", "A2", &[]interface{}{"V02", "ignored", "螺母", 0.25})
+
+	got, err := ReadFromFile[vendorRow](f, "Sheet1", WithNoHeader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []vendorRow{{"V01", "螺丝", 0.5}, {"V02", "螺母", 0.25}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// with a header, the position takes precedence over the header text
+	_ = f.InsertRows("Sheet1", 1, 1)
+	_ = f.SetSheetRow("Sheet1", "A1", &[]interface{}{"编码", "备注", "品名 2024Q1", "单价"})
+	got, err = ReadFromFile[vendorRow](f, "Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	type unmapped struct {
+		Code string `x-read:"编码"`
+	}
+	if _, err = ReadFromFile[unmapped](f, "Sheet1", WithNoHeader()); err == nil {
+		t.Error("expected an error for the field without a position")
+	}
+}
+
+func TestHeaderNormalization(t *testing.T) {
+	type contact struct {
+		Name      string `x-read:"name"`
+		Phone     string `x-read:"phone_code"`
+		AreaCode  string `x-read:"re:^电话.*区号$"`
+		Remark    string `x-read:"备注,re:^(说明|附注)"`
+		Unrelated string `x-read:"不存在;optional"`
+	}
+	f := excelize.NewFile()
+	defer f.Close()
+	_ = f.SetSheetRow("Sheet1", "A1", &[]interface{}{"Ｎａｍｅ", "Phone Code", "电话\n区号", "附注（可选）"})
+	_ = f.SetSheetRow("Sheet1", "A2", &[]interface{}{"张三", "13800000000", "025", "无"})
+
+	got, err := ReadFromFile[contact](f, "Sheet1", WithHeaderNormalization(NormalizeAll))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []contact{{"张三", "13800000000", "025", "无", ""}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// without the normalization the column names are only trimmed
+	if _, err = ReadFromFile[contact](f, "Sheet1"); err == nil {
+		t.Error("expected an error for the unnormalized header")
+	}
+	// the column names must be unique after the normalization
+	_ = f.SetCellValue("Sheet1", "E1", "NAME")
+	if _, err = ReadFromFile[contact](f, "Sheet1", WithHeaderNormalization(NormalizeAll)); err == nil {
+		t.Error("expected an error for the same normalized column names")
+	}
+}
+
+func TestScalarKinds(t *testing.T) {
+	type scalars struct {
+		Int8    int8     `x-read:"int8"`
+		Uint16  uint16   `x-read:"uint16"`
+		Uint    uint     `x-read:"uint"`
+		Float32 float32  `x-read:"float32"`
+		Count   *int     `x-read:"count"`
+		Name    *string  `x-read:"name"`
+		Level   *level   `x-read:"level"`
+		Price   *float64 `x-read:"price"`
+	}
+	f := excelize.NewFile()
+	defer f.Close()
+	_ = f.SetSheetRow("Sheet1", "A1", &[]interface{}{"int8", "uint16", "uint", "float32", "count", "name", "level", "price"})
+	_ = f.SetSheetRow("Sheet1", "A2", &[]interface{}{-128, 65535, "1.2E+3", 0.1, "025", "张三", "高"})
+
+	got, err := ReadFromFile[scalars](f, "Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d rows, want 1", len(got))
+	}
+	row := got[0]
+	if row.Int8 != -128 || row.Uint16 != 65535 || row.Uint != 1200 || row.Float32 != 0.1 {
+		t.Errorf("got %+v", row)
+	}
+	// the leading zero is not an octal prefix, such as the area code 025
+	if row.Count == nil || *row.Count != 25 || row.Name == nil || *row.Name != "张三" || row.Level == nil || *row.Level != 2 {
+		t.Errorf("got %+v", row)
+	}
+	if row.Price != nil {
+		t.Errorf("got price %v, want nil for the empty cell", *row.Price)
+	}
+
+	cases := map[string]interface{}{
+		"A2": 128,   // overflows int8
+		"B2": -1,    // negative uint
+		"C2": 1.5,   // not a whole number
+		"D2": 1e39,  // overflows float32
+		"E2": "abc", // not a number
+	}
+	for axis, value := range cases {
+		g := excelize.NewFile()
+		_ = g.SetSheetRow("Sheet1", "A1", &[]interface{}{"int8", "uint16", "uint", "float32", "count", "name", "level", "price"})
+		_ = g.SetSheetRow("Sheet1", "A2", &[]interface{}{0, 0, 0, 0, 0})
+		_ = g.SetCellValue("Sheet1", axis, value)
+		_, err := ReadFromFile[scalars](g, "Sheet1")
+		var cellErr *CellError
+		if !errors.As(err, &cellErr) || cellErr.Cell != axis {
+			t.Errorf("%s=%v: expected the error of the cell, got %v", axis, value, err)
+		}
+		g.Close()
+	}
+
+	type complexRow struct {
+		Value complex128 `x-read:"int8"`
+	}
+	if _, err = ReadFromFile[complexRow](f, "Sheet1"); err == nil {
+		t.Error("expected an error for the complex field")
+	}
+}
+
+type Address struct {
+	Province string `x-read:"省份"`
+	City     string `x-read:"城市"`
+}
+
+func TestNestedStructs(t *testing.T) {
+	type contact struct {
+		Name  string `x-read:"姓名"`
+		Phone string `x-read:"电话"`
+	}
+	type order struct {
+		Code string `x-read:"单号"`
+		Address
+		Receiver contact  `x-read:"prefix=收件人"`
+		Sender   *contact `x-read:"prefix=寄件人" x-write:"prefix=发件人"`
+		note     string
+	}
+	rows := []order{
+		{Code: "D001", Address: Address{"江苏", "南京"}, Receiver: contact{"张三", "13800000000"}, Sender: &contact{"李四", "13900000000"}},
+		{Code: "D002", Address: Address{"浙江", "杭州"}, Receiver: contact{"王五", "13700000000"}},
+	}
+	path := filepath.Join(t.TempDir(), "orders.xlsx")
+	if err := WriteToSheet(path, "订单", rows); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	header, _ := f.GetRows("订单")
+	want := []string{"单号", "省份", "城市", "收件人姓名", "收件人电话", "发件人姓名", "发件人电话"}
+	if !reflect.DeepEqual(header[0], want) {
+		t.Errorf("got header %v, want %v", header[0], want)
+	}
+
+	// the sender columns are written as 发件人, so the reading aliases 寄件人 are absent
+	got, err := ReadFromFile[order](f, "订单")
+	if err == nil {
+		t.Fatal("expected an error for the absent columns of the sender")
+	}
+	_ = f.SetSheetRow("订单", "F1", &[]interface{}{"寄件人姓名", "寄件人电话"})
+	got, err = ReadFromFile[order](f, "订单")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the nested pointer is allocated when its columns exist, the empty cells leave the fields empty
+	rows[1].Sender = &contact{}
+	if !reflect.DeepEqual(got, rows) {
+		t.Errorf("got %+v, want %+v", got, rows)
+	}
+}
+
+func TestCollectColumns(t *testing.T) {
+	type sales struct {
+		Product string             `x-read:"产品"`
+		Monthly []float64          `x-read:"re:^\\d+月$"`
+		Total   float64            `x-read:"合计"`
+		Regions map[string]float64 `x-read:"*"`
+	}
+	f := excelize.NewFile()
+	defer f.Close()
+	_ = f.SetSheetRow("Sheet1", "A1", &[]interface{}{"产品", "1月", "2月", "华东", "3月", "合计", "华南", ""})
+	_ = f.SetSheetRow("Sheet1", "A2", &[]interface{}{"螺丝", 1, 2, 5, 3, 6, nil, "无名"})
+	_ = f.SetSheetRow("Sheet1", "A3", &[]interface{}{"螺母", 4, 5, 7, 6, 15, 8})
+
+	got, err := ReadFromFile[sales](f, "Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []sales{
+		{"螺丝", []float64{1, 2, 3}, 6, map[string]float64{"华东": 5}},
+		{"螺母", []float64{4, 5, 6}, 15, map[string]float64{"华东": 7, "华南": 8}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	type badCatchAll struct {
+		Rest []string `x-read:"*"`
+	}
+	if _, err = ReadFromFile[badCatchAll](f, "Sheet1"); err == nil {
+		t.Error("expected an error for the catch-all field that is not a map")
+	}
+}
+
+func TestSplitCells(t *testing.T) {
+	type route struct {
+		Name   string      `x-read:"线路"`
+		Cities []string    `x-read:"城市;split=,"`
+		Stops  []int       `x-read:"站点;split=|"`
+		Days   []time.Time `x-read:"日期;split=、"`
+	}
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+	rows := []route{
+		{"沪宁线", []string{"南京", "苏州", "无锡"}, []int{1, 2, 3}, []time.Time{day(1), day(2)}},
+		{"空线路", nil, nil, nil},
+	}
+	path := filepath.Join(t.TempDir(), "routes.xlsx")
+	if err := WriteToSheet(path, "Sheet1", rows); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for axis, want := range map[string]string{"B2": "南京,苏州,无锡", "C2": "1|2|3", "D2": "2024-05-01、2024-05-02"} {
+		if got, _ := f.GetCellValue("Sheet1", axis); got != want {
+			t.Errorf("%s: got %s, want %s", axis, got, want)
+		}
+	}
+
+	// the spaces and the empty values are left out on reading
+	_ = f.SetCellValue("Sheet1", "B2", " 南京， 苏州,无锡, ")
+	got, err := ReadFromFile[route](f, "Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows[0].Cities = []string{"南京， 苏州", "无锡"}
+	rows[1] = route{"空线路", []string{}, []int{}, []time.Time{}}
+	if !reflect.DeepEqual(got, rows) {
+		t.Errorf("got %v, want %v", got, rows)
+	}
+
+	_ = f.SetCellValue("Sheet1", "C2", "1|x|3")
+	var cellErr *CellError
+	if _, err = ReadFromFile[route](f, "Sheet1"); !errors.As(err, &cellErr) || cellErr.Cell != "C2" {
+		t.Errorf("expected the error of the cell C2, got %v", err)
+	}
+}
+
+func TestBoolValues(t *testing.T) {
+	type answer struct {
+		Value  bool  `x-read:"值"`
+		Answer *bool `x-read:"回答"`
+	}
+	f := excelize.NewFile()
+	defer f.Close()
+	_ = f.SetSheetRow("Sheet1", "A1", &[]interface{}{"值", "回答"})
+	values := []interface{}{"是", "否", "Y", "n", "yes", "No", "√", "×", true, false, ""}
+	for i, value := range values {
+		_ = f.SetSheetRow("Sheet1", fmt.Sprintf("A%d", i+2), &[]interface{}{value, value})
+	}
+	got, err := ReadFromFile[answer](f, "Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, row := range got {
+		want := i%2 == 0 && i < len(values)-1
+		if row.Value != want {
+			t.Errorf("%v: got %v, want %v", values[i], row.Value, want)
+		}
+		if i == len(values)-1 {
+			if row.Answer != nil {
+				t.Errorf("got %v, want nil for the empty cell", *row.Answer)
+			}
+		} else if row.Answer == nil || *row.Answer != want {
+			t.Errorf("%v: got %v, want %v", values[i], row.Answer, want)
+		}
+	}
+
+	_ = f.SetSheetRow("Sheet1", "A2", &[]interface{}{"有", "是"})
+	var cellErr *CellError
+	if _, err = ReadFromFile[answer](f, "Sheet1"); !errors.As(err, &cellErr) || cellErr.Cell != "A2" {
+		t.Errorf("expected the error of the cell A2, got %v", err)
+	}
+
+	g := excelize.NewFile()
+	defer g.Close()
+	_ = g.SetSheetRow("Sheet1", "A1", &[]interface{}{"值", "回答"})
+	_ = g.SetSheetRow("Sheet1", "A2", &[]interface{}{"有", "是"})
+	_ = g.SetSheetRow("Sheet1", "A3", &[]interface{}{"无", "否"})
+	got, err = ReadFromFile[answer](g, "Sheet1", WithBoolValues([]string{"有", "是"}, []string{"无", "否"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got[0].Value || !*got[0].Answer || got[1].Value || *got[1].Answer {
+		t.Errorf("got %+v", got)
+	}
+	// the boolean cells are read with a custom vocabulary
+	_ = g.SetSheetRow("Sheet1", "A4", &[]interface{}{true, false})
+	got, err = ReadFromFile[answer](g, "Sheet1", WithBoolValues([]string{"有", "是"}, []string{"无", "否"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || !got[2].Value || *got[2].Answer {
+		t.Errorf("got %+v", got)
+	}
+	// the custom vocabulary replaces the default one
+	_ = g.SetCellValue("Sheet1", "A3", "N")
+	if _, err = ReadFromFile[answer](g, "Sheet1", WithBoolValues([]string{"有"}, []string{"无"})); err == nil {
+		t.Error("expected an error for the value out of the custom vocabulary")
+	}
+}
+
+func TestTimeFields(t *testing.T) {
+	type shift struct {
+		Day      Date          `x-read:"日期"`
+		Start    time.Time     `x-read:"开始"`
+		Length   time.Duration `x-read:"时长"`
+		Deadline time.Time     `x-read:"截止;layout=2006/01/02 15:04"`
+	}
+	shanghai := time.FixedZone("CST", 8*60*60)
+	rows := []shift{
+		{Date{2024, 5, 1}, time.Date(2024, 5, 1, 8, 30, 0, 0, shanghai), 36*time.Hour + 30*time.Minute, time.Date(2024, 5, 3, 18, 0, 0, 0, shanghai)},
+	}
+	path := filepath.Join(t.TempDir(), "shifts.xlsx")
+	if err := WriteToSheet(path, "Sheet1", rows, WithLocation(shanghai)); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for axis, want := range map[string]string{"C2": "36:30:00", "D2": "2024/05/03 18:00"} {
+		if got, _ := f.GetCellValue("Sheet1", axis); got != want {
+			t.Errorf("%s: got %s, want %s", axis, got, want)
+		}
+	}
+
+	got, err := ReadFromFile[shift](f, "Sheet1", WithLocation(shanghai))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Day != rows[0].Day || !got[0].Start.Equal(rows[0].Start) ||
+		got[0].Length != rows[0].Length || !got[0].Deadline.Equal(rows[0].Deadline) {
+		t.Errorf("got %+v, want %+v", got, rows)
+	}
+	// the serial numbers are the clock in UTC by default
+	got, _ = ReadFromFile[shift](f, "Sheet1")
+	if want := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC); !got[0].Start.Equal(want) {
+		t.Errorf("got %v, want %v", got[0].Start, want)
+	}
+
+	_ = f.SetSheetRow("Sheet1", "A2", &[]interface{}{"2024年5月2日", "2024-05-02 09:00", "1:30", "2024-05-04 18:00"})
+	_, err = ReadFromFile[shift](f, "Sheet1")
+	var cellErr *CellError
+	if !errors.As(err, &cellErr) || cellErr.Cell != "D2" {
+		t.Fatalf("expected the error of the cell D2 out of the layout, got %v", err)
+	}
+	_ = f.SetCellValue("Sheet1", "D2", "2024/05/04 18:00")
+	got, err = ReadFromFile[shift](f, "Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Day != (Date{2024, 5, 2}) || got[0].Length != 90*time.Minute || got[0].Start.Hour() != 9 {
+		t.Errorf("got %+v", got[0])
+	}
+}
+
+func TestCompileSchema(t *testing.T) {
+	if err := CompileSchema[City](); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := schemas.Load(reflect.TypeOf(City{})); !ok {
+		t.Error("expected the cached schema")
+	}
+	type order struct {
+		Code string `x-read:"单号"`
+		Address
+		Note string `x-read:"备注" x-write:"-"`
+	}
+	s, err := schemaOf(reflect.TypeOf(order{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header []string
+	for _, field := range s.writeFields {
+		header = append(header, field.tag.aliases[0])
+	}
+	if want := []string{"单号", "省份", "城市"}; !reflect.DeepEqual(header, want) {
+		t.Errorf("got %v, want %v", header, want)
+	}
+
+	type badPattern struct {
+		Phone string `x-read:"re:^电话("`
+	}
+	if err = CompileSchema[badPattern](); err == nil {
+		t.Error("expected an error for the invalid regular expression")
+	}
+	if err = CompileSchema[int](); err == nil {
+		t.Error("expected an error for the type that is not a struct")
+	}
+}
+
+func TestLogger(t *testing.T) {
+	type city struct {
+		City string `x-read:"城市"`
+	}
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	f := newTemplateFile(t)
+	if _, err := ReadFromFile[city](f, "Sheet1", WithHeaderRow(5), WithLogger(logger)); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"header located", "row=5", "field mapped", "column=城市", "sheet read"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("the log has no %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestReadOptions(t *testing.T) {
+	type payment struct {
+		Name   string    `x-read:"姓名"`
+		Amount float64   `x-read:"金额"`
+		Count  int       `x-read:"笔数"`
+		Date   time.Time `x-read:"日期"`
+	}
+	f := excelize.NewFile()
+	defer f.Close()
+	_ = f.SetSheetRow("Sheet1", "A1", &[]interface{}{"姓名", "金额", "笔数", "日期"})
+	_ = f.SetSheetRow("Sheet1", "A2", &[]interface{}{" 张三 ", "1.234,5", "1.000", 1})
+	_ = f.SetSheetRow("Sheet1", "A3", &[]interface{}{"李四", 2.5, 3, 1})
+
+	german := WithLocale(Locale{DecimalSeparator: ',', GroupSeparator: '.'})
+	got, err := ReadFromFile[payment](f, "Sheet1", german, WithTrimSpace(false), WithDate1904(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []payment{
+		{" 张三 ", 1234.5, 1000, time.Date(1904, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"李四", 2.5, 3, time.Date(1904, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	// the numbers as text are in the Go syntax without the locale
+	if _, err = ReadFromFile[payment](f, "Sheet1"); err == nil {
+		t.Error("expected an error for the number in the German syntax")
+	}
+
+	_ = f.SetCellValue("Sheet1", "E1", "备注")
+	if _, err = ReadFromFile[payment](f, "Sheet1", german); err != nil {
+		t.Errorf("the unmapped column is ignored by default, got %v", err)
+	}
+	var headerErr *HeaderError
+	_, err = ReadFromFile[payment](f, "Sheet1", german, WithStrict())
+	if !errors.Is(err, ErrUnmappedColumn) || !errors.As(err, &headerErr) || headerErr.Column != "备注" {
+		t.Errorf("expected an error for the unmapped column, got %v", err)
+	}
+}
+
+func TestContextAndLimits(t *testing.T) {
+	type city struct {
+		City string `x-read:"城市"`
+	}
+	f := newTemplateFile(t)
+	header := WithHeaderRow(5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ReadFromFileContext[city](ctx, f, "Sheet1", header); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	// the Reader stops at the next row after the ctx is canceled
+	readerCtx, cancelReader := context.WithCancel(context.Background())
+	r, err := NewReaderContext[city](readerCtx, f, "Sheet1", header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if !r.Next() {
+		t.Fatalf("expected a row, got %v", r.Err())
+	}
+	cancelReader()
+	if r.Next() || !errors.Is(r.Err(), context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", r.Err())
+	}
+
+	cases := map[string]struct {
+		opts []Option
+		want error
+	}{
+		"rows":        {[]Option{header, WithMaxRows(3)}, ErrTooManyRows},
+		"columns":     {[]Option{header, WithMaxColumns(2)}, ErrTooManyColumns},
+		"cell length": {[]Option{header, WithMaxCellLength(20)}, ErrCellTooLong},
+	}
+	for name, c := range cases {
+		if _, err := ReadFromFile[city](f, "Sheet1", c.opts...); !errors.Is(err, c.want) {
+			t.Errorf("%s: expected %v, got %v", name, c.want, err)
+		}
+	}
+	if _, err := ReadFromFile[city](f, "Sheet1", header, WithMaxRows(5), WithMaxColumns(3), WithMaxCellLength(30)); err != nil {
+		t.Errorf("expected no error within the limits, got %v", err)
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ReadFromBytes[city](buf.Bytes(), "Sheet1", header, WithMaxSize(1024)); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("expected ErrFileTooLarge, got %v", err)
+	}
+	if _, err = ReadFromBytes[city](buf.Bytes(), "Sheet1", header, WithMaxSize(1<<20)); err != nil {
+		t.Errorf("expected no error within the size limit, got %v", err)
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	for axis, value := range map[string]any{"A1": "省份", "B1": "省会", "C1": "省份", "A2": "江苏", "B2": "南京"} {
+		if err := f.SetCellValue("Sheet1", axis, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := f.NewSheet("空"); err != nil {
+		t.Fatal(err)
+	}
+	type capital struct {
+		Capital string `x-read:"省会"`
+		Code    int    `x-read:"邮编"`
+	}
+	type count struct {
+		Count int `x-read:"省份"`
+	}
+
+	_, err := ReadFromFile[Province](f, "区县")
+	if !errors.Is(err, ErrSheetNotFound) || !errors.As(err, new(excelize.ErrSheetNotExist)) {
+		t.Errorf("expected ErrSheetNotFound wrapping the excelize error, got %v", err)
+	}
+	if _, err = ReadFromFile[Province](f, "空"); !errors.Is(err, ErrEmptySheet) {
+		t.Errorf("expected ErrEmptySheet, got %v", err)
+	}
+
+	var headerErr *HeaderError
+	_, err = ReadFromFile[Province](f, "Sheet1")
+	if !errors.Is(err, ErrDuplicateHeader) || !errors.As(err, &headerErr) || headerErr.Sheet != "Sheet1" || headerErr.Column != "省份" {
+		t.Errorf("expected ErrDuplicateHeader of the column 省份, got %v", err)
+	}
+	if err = f.SetCellValue("Sheet1", "C1", "人口"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadFromFile[capital](f, "Sheet1")
+	if !errors.Is(err, ErrMissingColumn) || !errors.As(err, &headerErr) || headerErr.Field != "Code" {
+		t.Errorf("expected ErrMissingColumn of the field Code, got %v", err)
+	}
+
+	_, err = ReadFromFile[count](f, "Sheet1")
+	var cellErr *CellError
+	if !errors.Is(err, ErrConversion) || !errors.As(err, &cellErr) || cellErr.Cell != "A2" {
+		t.Errorf("expected ErrConversion of the cell A2, got %v", err)
+	}
+
+	type workbook struct {
+		Missing []Province `x-sheet:"区县"`
+		Counts  []count    `x-sheet:"Sheet1"`
+	}
+	_, err = ReadWorkbookFromFile[workbook](f)
+	if !errors.Is(err, ErrSheetNotFound) || !errors.Is(err, ErrConversion) {
+		t.Errorf("expected the sentinels through the WorkbookError, got %v", err)
+	}
+
+	// the causes of the encoders and the regular expressions are wrapped
+	errEncode := errors.New("encode failed")
+	conv := NewConverters()
+	RegisterNamedEncoder(conv, "fail", func(int) (interface{}, error) { return nil, errEncode })
+	type encoded struct {
+		Value int `x-write:"值;conv=fail"`
+	}
+	path := filepath.Join(t.TempDir(), "encoded.xlsx")
+	if err = WriteToSheet(path, "Sheet1", []encoded{{1}}, WithConverters(conv)); !errors.Is(err, errEncode) {
+		t.Errorf("expected the error of the encoder, got %v", err)
+	}
+	type pattern struct {
+		Values []int `x-read:"re:("`
+	}
+	var syntaxErr *syntax.Error
+	if err = CompileSchema[pattern](); !errors.As(err, &syntaxErr) {
+		t.Errorf("expected a *syntax.Error, got %v", err)
+	}
+}
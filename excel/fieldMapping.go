@@ -3,12 +3,61 @@ package excel
 import "reflect"
 
 type FieldMappingItem struct {
-	// the fieldName of the struct represent the row.
+	// the fieldName of the struct represent the row, the path such as Receiver.Name for a nested field.
 	FieldName string
+	// the index sequence of the field in the struct represent the row.
+	Index []int
 	// the column name of the sheet, in the other word, is the header row of the sheet.
 	ColName string
-	// the index of columns in the header row.
+	// the index of columns in the header row, starts from 0. it is -1 if the optional column is absent.
 	ColIndex int
 	// the fieldType
 	FieldType reflect.Type
+	// the columns collected by a slice field such as x-read:"re:^\d+月$", or captured by a map field tagged
+	// with x-read:"*". ColIndex is the first one of them, or -1 if no column is collected.
+	ColIndexes []int
+	ColNames   []string
+	// whether the field collects several columns.
+	collect bool
+	// the separator of the split option, empty if the cell is not split.
+	sep string
+	// whether the field reads the formatted text and the raw value of the cell, see cellViews.
+	text, raw bool
+	// the parsed x-read or x-write tag of the field.
+	tag *fieldTag
+	// the converter registered for the field, nil if the field is converted by the built-in conversions.
+	converter *converter
+}
+
+// the item of the field mapped to the column, the colIndex is -1 if the field is not mapped
+func newFieldMappingItem(field structField, colIndex int, colName string) *FieldMappingItem {
+	item := &FieldMappingItem{
+		FieldName: field.path,
+		Index:     field.index,
+		FieldType: field.Type,
+		ColIndex:  colIndex,
+		ColName:   colName,
+		tag:       field.tag,
+	}
+	if field.Type.Kind() == reflect.Slice {
+		item.sep, _ = field.tag.option(splitOption)
+	}
+	return item
+}
+
+// the type of the value decoded from a cell, it is the element type of a field collecting several columns
+// or splitting the cell to several values
+func (item *FieldMappingItem) valueType() reflect.Type {
+	if item.collect {
+		return item.FieldType.Elem()
+	}
+	if _, ok := item.split(); ok {
+		return item.FieldType.Elem()
+	}
+	return item.FieldType
+}
+
+// the separator of the values in a cell, ok is false unless the slice field is tagged with the split option
+func (item *FieldMappingItem) split() (string, bool) {
+	return item.sep, item.sep != ""
 }
@@ -0,0 +1,120 @@
+package excel
+
+import (
+	"encoding"
+	"reflect"
+	"strings"
+)
+
+// structField is a field of the row struct mapped to a column, the fields of the embedded structs
+// and the nested structs tagged with the prefix option are flattened into the fields of the row
+type structField struct {
+	reflect.StructField
+	// the path of the field from the row struct, such as Receiver.Name
+	path string
+	// the index sequence of the field for fieldByIndex
+	index []int
+	// the tag of the field, the aliases are prefixed by the prefix options of the parent structs
+	tag *fieldTag
+	// the column index set by the col or idx option, positional is false if neither is set
+	colIndex   int
+	positional bool
+}
+
+// Flatten the fields of the struct t, the tag of a field is returned by tagOf and the field is skipped if it is nil.
+// An embedded struct without column names is flattened, such as an Address struct shared by several rows,
+// and a nested struct tagged with x-read:"prefix=收件人" is flattened with its column names prefixed by 收件人.
+func structFields(t reflect.Type, tagOf func(reflect.StructField) *fieldTag) []structField {
+	return appendStructFields(nil, t, nil, "", "", tagOf)
+}
+
+func appendStructFields(fields []structField, t reflect.Type, index []int, path, prefix string,
+	tagOf func(reflect.StructField) *fieldTag) []structField {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := tagOf(field)
+		if tag == nil {
+			continue
+		}
+		fieldIndex := append(index[:len(index):len(index)], i)
+		fieldPath := path + field.Name
+		fieldPrefix, hasPrefix := tag.option(prefixOption)
+		if st := nestedStructType(field); st != nil && (hasPrefix || (field.Anonymous && len(tag.aliases) == 0)) {
+			fields = appendStructFields(fields, st, fieldIndex, fieldPath+".", prefix+fieldPrefix, tagOf)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		fields = append(fields, structField{
+			StructField: field,
+			path:        fieldPath,
+			index:       fieldIndex,
+			tag:         tag.withPrefix(prefix),
+		})
+	}
+	return fields
+}
+
+// the struct type of a field that can be flattened, nil if the field is not a struct or a pointer to a struct.
+// The embedded structs decoding or encoding themselves, such as time.Time, are not flattened.
+func nestedStructType(field reflect.StructField) reflect.Type {
+	t := field.Type
+	if t.Kind() == reflect.Pointer {
+		// the pointer to an unexported struct can't be allocated
+		if !field.IsExported() {
+			return nil
+		}
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType {
+		return nil
+	}
+	if field.Anonymous {
+		pt := reflect.PointerTo(t)
+		for _, it := range []reflect.Type{cellUnmarshalerType, textUnmarshalerType, cellMarshalerType, textMarshalerType} {
+			if pt.Implements(it) {
+				return nil
+			}
+		}
+	}
+	return t
+}
+
+// The field of the row by the index sequence, the nil pointers to the nested structs are allocated if alloc is true,
+// otherwise ok is false when the field is behind a nil pointer.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (field reflect.Value, ok bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// the copy of the tag with the column names prefixed, the regular expressions are not prefixed
+func (ft *fieldTag) withPrefix(prefix string) *fieldTag {
+	if prefix == "" {
+		return ft
+	}
+	prefixed := &fieldTag{options: ft.options}
+	for _, alias := range ft.aliases {
+		if !strings.HasPrefix(alias, regexAliasPrefix) {
+			alias = prefix + alias
+		}
+		prefixed.aliases = append(prefixed.aliases, alias)
+	}
+	return prefixed
+}
+
+var (
+	cellMarshalerType = reflect.TypeOf((*CellMarshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
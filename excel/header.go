@@ -0,0 +1,331 @@
+package excel
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// the number of the rows scanned to detect the header by default
+const defaultHeaderScanRows = 20
+
+// Read the column names of the header, the rows read after the header are kept for the data.
+// With WithHeaderRows, the header rows are combined to the paths such as 人口/男.
+func (sr *sheetReader) readHeader() ([]string, error) {
+	first, err := sr.locateHeader()
+	if err != nil {
+		return nil, err
+	}
+	sr.cfg.logger.Debug("excel: header located", "sheet", sr.sheetName, "row", first.num)
+	sr.headerEnd = first.num + max(sr.cfg.headerRows, 1) - 1
+	if sr.cfg.headerRows <= 1 {
+		return first.cells, nil
+	}
+	return sr.combineHeaderRows(first)
+}
+
+// Locate the first header row, the rows read after it are kept for the data.
+// The header is the row set by WithHeaderRow, the row detected by WithHeaderDetection, or the first non-empty row.
+func (sr *sheetReader) locateHeader() (sheetRow, error) {
+	switch {
+	case sr.cfg.headerRow > 0:
+		for {
+			row, ok := sr.readRow()
+			if !ok {
+				break
+			}
+			if row.num == sr.cfg.headerRow {
+				return row, nil
+			}
+			if row.num > sr.cfg.headerRow {
+				sr.pending = append(sr.pending, row)
+				break
+			}
+		}
+		if sr.err != nil {
+			return sheetRow{}, sr.err
+		}
+		return sheetRow{}, fmt.Errorf("%w: the header row %d is empty", ErrEmptySheet, sr.cfg.headerRow)
+	case sr.cfg.detectHeader:
+		return sr.detectHeader()
+	}
+	row, ok := sr.readRow()
+	if !ok {
+		if sr.err != nil {
+			return sheetRow{}, sr.err
+		}
+		return sheetRow{}, fmt.Errorf("%w: No data in the sheet.", ErrEmptySheet)
+	}
+	return row, nil
+}
+
+// the header is the row matching the most fields among the scanned rows, the first one wins a tie
+func (sr *sheetReader) detectHeader() (sheetRow, error) {
+	tags := make([]*fieldTag, 0, len(sr.schema.readFields))
+	for _, field := range sr.schema.readFields {
+		tags = append(tags, field.tag)
+	}
+	scanRows := sr.cfg.headerScanRows
+	if scanRows <= 0 {
+		scanRows = defaultHeaderScanRows
+	}
+
+	var scanned []sheetRow
+	best, bestScore := -1, 0
+	for {
+		row, ok := sr.readRow()
+		if !ok {
+			break
+		}
+		if row.num > scanRows {
+			scanned = append(scanned, row)
+			break
+		}
+		if score := matchScore(row.cells, tags, sr.cfg.normalization); score > bestScore {
+			best, bestScore = len(scanned), score
+		}
+		scanned = append(scanned, row)
+	}
+	if sr.err != nil {
+		return sheetRow{}, sr.err
+	}
+	if best == -1 {
+		return sheetRow{}, &HeaderError{Sheet: sr.sheetName,
+			Err: fmt.Errorf("%w: no header matching the fields is found in the first %d rows", ErrMissingColumn, scanRows)}
+	}
+	sr.pending = append(scanned[best+1:], sr.pending...)
+	return scanned[best], nil
+}
+
+// Read the header rows below the first one and combine them column by column. The value of a merged cell
+// is propagated to every cell it covers, then the distinct values from top to bottom are joined by '/'.
+// For example, a merged cell 人口 above the cells 男 and 女 gives the paths 人口/男 and 人口/女.
+// The merged cells are looked up in the workbook, which loads the whole worksheet in memory.
+func (sr *sheetReader) combineHeaderRows(first sheetRow) ([]string, error) {
+	lastRowNum := first.num + sr.cfg.headerRows - 1
+	rows := make([][]string, sr.cfg.headerRows)
+	rows[0] = first.cells
+	for {
+		row, ok := sr.readRow()
+		if !ok {
+			break
+		}
+		if row.num > lastRowNum {
+			sr.pending = append([]sheetRow{row}, sr.pending...)
+			break
+		}
+		rows[row.num-first.num] = row.cells
+	}
+	if sr.err != nil {
+		return nil, sr.err
+	}
+
+	sr.cfg.fileMu.Lock()
+	mergeCells, err := sr.f.GetMergeCells(sr.sheetName)
+	sr.cfg.fileMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	for _, mc := range mergeCells {
+		startCol, startRow, err := excelize.CellNameToCoordinates(mc.GetStartAxis())
+		if err != nil {
+			return nil, err
+		}
+		endCol, endRow, err := excelize.CellNameToCoordinates(mc.GetEndAxis())
+		if err != nil {
+			return nil, err
+		}
+		if endRow < first.num || startRow > lastRowNum {
+			continue
+		}
+		value := mc.GetCellValue()
+		if startRow >= first.num {
+			value = getCell(rows[startRow-first.num], startCol-1)
+		}
+		for r := max(startRow, first.num); r <= min(endRow, lastRowNum); r++ {
+			for c := startCol; c <= endCol; c++ {
+				rows[r-first.num] = setCell(rows[r-first.num], c-1, value)
+			}
+		}
+	}
+
+	width := 0
+	for _, cells := range rows {
+		width = max(width, len(cells))
+	}
+	header := make([]string, width)
+	for c := range header {
+		var parts []string
+		for _, cells := range rows {
+			value := strings.TrimSpace(getCell(cells, c))
+			if value != "" && (len(parts) == 0 || parts[len(parts)-1] != value) {
+				parts = append(parts, value)
+			}
+		}
+		header[c] = strings.Join(parts, "/")
+	}
+	return header, nil
+}
+
+// set the cell of the column, the cells are extended if the column is out of the range
+func setCell(cells []string, colIndex int, value string) []string {
+	for len(cells) <= colIndex {
+		cells = append(cells, "")
+	}
+	cells[colIndex] = value
+	return cells
+}
+
+// the number of the fields that have an alias in the cells,
+// the alias of a multi-row header such as 人口/男 matches its top level 人口 as well
+func matchScore(cells []string, tags []*fieldTag, n Normalization) int {
+	h, _ := newHeaderIndex(cells, n, true)
+	score := 0
+	for _, tag := range tags {
+		candidates := &fieldTag{aliases: tag.aliases, patterns: tag.patterns}
+		for _, alias := range tag.aliases {
+			if topLevel, _, ok := strings.Cut(alias, "/"); ok && !strings.HasPrefix(alias, regexAliasPrefix) {
+				candidates.aliases = append(candidates.aliases[:len(candidates.aliases):len(candidates.aliases)], topLevel)
+			}
+		}
+		if _, ok, _ := h.find(candidates); ok {
+			score++
+		}
+	}
+	return score
+}
+
+// headerIndex finds the columns of the header by the aliases of the x-read tag
+type headerIndex struct {
+	// the trimmed column names
+	names []string
+	// the column names normalized by the rules of WithHeaderNormalization
+	keys []string
+	// the normalized column name to the index of the column
+	indexes map[string]int
+	// the columns already mapped to a field
+	taken []bool
+	norm  Normalization
+}
+
+// Build the index of the header, the column names must be unique after the normalization.
+// In the lenient mode the first one of the same column names is indexed instead of returning an error.
+func newHeaderIndex(header []string, n Normalization, lenient bool) (*headerIndex, error) {
+	h := &headerIndex{
+		names:   make([]string, len(header)),
+		keys:    make([]string, len(header)),
+		indexes: make(map[string]int, len(header)),
+		taken:   make([]bool, len(header)),
+		norm:    n,
+	}
+	for colIndex, cell := range header {
+		h.names[colIndex] = strings.TrimSpace(cell)
+		key := n.normalize(cell)
+		h.keys[colIndex] = key
+		if key == "" {
+			// the columns without a name are not mapped
+			continue
+		}
+		if _, ok := h.indexes[key]; ok {
+			if lenient {
+				continue
+			}
+			return nil, &HeaderError{Column: h.names[colIndex], Err: fmt.Errorf("%w: The same column name exists in the sheet.", ErrDuplicateHeader)}
+		}
+		h.indexes[key] = colIndex
+	}
+	return h, nil
+}
+
+// Find the column of the first alias that matches a column not mapped yet, the aliases are tried in order.
+// A regular expression alias such as re:^电话.*区号$ matches the normalized column names from left to right.
+func (h *headerIndex) find(tag *fieldTag) (int, bool, error) {
+	for _, alias := range tag.aliases {
+		if strings.HasPrefix(alias, regexAliasPrefix) {
+			re, err := tag.pattern(alias)
+			if err != nil {
+				return -1, false, err
+			}
+			for colIndex, key := range h.keys {
+				if key != "" && !h.taken[colIndex] && re.MatchString(key) {
+					return colIndex, true, nil
+				}
+			}
+			continue
+		}
+		if colIndex, ok := h.indexes[h.norm.normalize(alias)]; ok && !h.taken[colIndex] {
+			return colIndex, true, nil
+		}
+	}
+	return -1, false, nil
+}
+
+// Find every column not mapped yet that matches any of the aliases, in the order of the header.
+// It collects the columns such as 1月…12月 by x-read:"re:^\d+月$".
+func (h *headerIndex) findAll(tag *fieldTag) ([]int, error) {
+	keys := make(map[string]bool, len(tag.aliases))
+	var patterns []*regexp.Regexp
+	for _, alias := range tag.aliases {
+		if strings.HasPrefix(alias, regexAliasPrefix) {
+			re, err := tag.pattern(alias)
+			if err != nil {
+				return nil, err
+			}
+			patterns = append(patterns, re)
+			continue
+		}
+		keys[h.norm.normalize(alias)] = true
+	}
+	var colIndexes []int
+	for colIndex, key := range h.keys {
+		if key == "" || h.taken[colIndex] {
+			continue
+		}
+		matched := keys[key]
+		for _, re := range patterns {
+			matched = matched || re.MatchString(key)
+		}
+		if matched {
+			colIndexes = append(colIndexes, colIndex)
+		}
+	}
+	return colIndexes, nil
+}
+
+// every named column not mapped yet, in the order of the header
+func (h *headerIndex) rest() []int {
+	var colIndexes []int
+	for colIndex, key := range h.keys {
+		if key != "" && !h.taken[colIndex] {
+			colIndexes = append(colIndexes, colIndex)
+		}
+	}
+	return colIndexes
+}
+
+// the column name of the header, it is empty if the column is out of the header
+func (h *headerIndex) name(colIndex int) string {
+	return getCell(h.names, colIndex)
+}
+
+// mark the column as mapped, it is not matched by the other fields
+func (h *headerIndex) take(colIndex int) {
+	if colIndex >= 0 && colIndex < len(h.taken) {
+		h.taken[colIndex] = true
+	}
+}
+
+// whether the first non-empty cell of the row is the stop marker
+func isStopRow(cells []string, stopMarker string) bool {
+	if stopMarker == "" {
+		return false
+	}
+	for _, cell := range cells {
+		if cell = strings.TrimSpace(cell); cell != "" {
+			return cell == stopMarker
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package excel
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// Normalization is a set of the rules applied to the column names of the header and the aliases
+// of the x-read tag before they are compared, see WithHeaderNormalization.
+type Normalization uint
+
+const (
+	// Unicode NFKC normalization, such as ① to 1 and the full-width space to a space.
+	NormalizeNFKC Normalization = 1 << iota
+	// fold the full-width and the half-width characters, such as Ａ to A and ｶ to カ.
+	NormalizeWidth
+	// case folding, such as Phone to phone.
+	NormalizeCase
+	// remove the spaces and the line breaks, such as "电话 区号" or "电话\n区号" to 电话区号.
+	NormalizeSpace
+	// remove the punctuations, such as phone_code to phonecode and 金额（元） to 金额元.
+	NormalizePunct
+
+	// all the rules above.
+	NormalizeAll = NormalizeNFKC | NormalizeWidth | NormalizeCase | NormalizeSpace | NormalizePunct
+)
+
+// normalize the column name by the rules, the name is trimmed in any case
+func (n Normalization) normalize(name string) string {
+	name = strings.TrimSpace(name)
+	if n == 0 {
+		return name
+	}
+	if n&NormalizeNFKC != 0 {
+		name = norm.NFKC.String(name)
+	}
+	if n&NormalizeWidth != 0 {
+		name = width.Fold.String(name)
+	}
+	if n&NormalizeCase != 0 {
+		name = cases.Fold().String(name)
+	}
+	if n&(NormalizeSpace|NormalizePunct) != 0 {
+		name = strings.Map(func(r rune) rune {
+			if n&NormalizeSpace != 0 && unicode.IsSpace(r) {
+				return -1
+			}
+			if n&NormalizePunct != 0 && unicode.IsPunct(r) {
+				return -1
+			}
+			return r
+		}, name)
+	}
+	return strings.TrimSpace(name)
+}
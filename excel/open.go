@@ -0,0 +1,74 @@
+package excel
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// open the workbook of the file, the uncompressed size is checked by WithMaxSize
+func openFile(filepath string, cfg *config) (*excelize.File, error) {
+	if cfg.maxSize <= 0 {
+		f, err := excelize.OpenFile(filepath)
+		if err != nil {
+			return nil, fmt.Errorf("file opening failed. %s: %w", filepath, err)
+		}
+		return f, nil
+	}
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("file opening failed. %s: %w", filepath, err)
+	}
+	defer file.Close()
+	return openReader(file, cfg)
+}
+
+// open the workbook read from r, the uncompressed size is checked by WithMaxSize
+func openReader(r io.Reader, cfg *config) (*excelize.File, error) {
+	if cfg.maxSize <= 0 {
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("file opening failed. %w", err)
+		}
+		return f, nil
+	}
+	// excelize reads the whole workbook in memory as well, the compressed size is limited first
+	data, err := io.ReadAll(io.LimitReader(r, cfg.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("file opening failed. %w", err)
+	}
+	if int64(len(data)) > cfg.maxSize {
+		return nil, fmt.Errorf("%w: the file is larger than %d bytes", ErrFileTooLarge, cfg.maxSize)
+	}
+	if err = checkUnzipSize(data, cfg.maxSize); err != nil {
+		return nil, err
+	}
+	f, err := excelize.OpenReader(bytes.NewReader(data), excelize.Options{
+		UnzipSizeLimit:    cfg.maxSize,
+		UnzipXMLSizeLimit: min(cfg.maxSize, excelize.StreamChunkSize),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("file opening failed. %w", err)
+	}
+	return f, nil
+}
+
+// the sum of the uncompressed sizes of the files in the zip package should not exceed the maxSize
+func checkUnzipSize(data []byte, maxSize int64) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("file opening failed. %w", err)
+	}
+	var size uint64
+	for _, file := range zr.File {
+		size += file.UncompressedSize64
+		if size > uint64(maxSize) {
+			return fmt.Errorf("%w: the file is larger than %d bytes when uncompressed", ErrFileTooLarge, maxSize)
+		}
+	}
+	return nil
+}
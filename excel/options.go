@@ -0,0 +1,320 @@
+package excel
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Option configures how a sheet is read or written.
+type Option func(*config)
+
+// the configuration built from the options of a call
+type config struct {
+	// keep reading after a row failed to decode, see WithCollectErrors.
+	collectErrors bool
+	// the conversions registered by the caller, see WithConverters.
+	converters *Converters
+	// the sheet has no header, see WithNoHeader.
+	noHeader bool
+	// the number of the header row, 0 means the first non-empty row, see WithHeaderRow.
+	headerRow int
+	// the number of the rows of a hierarchical header, see WithHeaderRows.
+	headerRows int
+	// detect the header in the first headerScanRows rows, see WithHeaderDetection.
+	detectHeader   bool
+	headerScanRows int
+	// the first and the last row of the data, 0 means not limited, see WithDataRows.
+	dataStartRow int
+	dataEndRow   int
+	// the reading stops at the row starting with the stopMarker, see WithStopMarker.
+	stopMarker string
+	// the rules to normalize the column names before matching, see WithHeaderNormalization.
+	normalization Normalization
+	// the upper-cased text of the bool values, nil means the defaultBoolValues, see WithBoolValues.
+	boolValues map[string]bool
+	// the location of the dates without a time zone, nil means UTC, see WithLocation.
+	location *time.Location
+	// the logger of the reading and the writing, it discards the records by default, see WithLogger.
+	logger *slog.Logger
+	// keep the spaces around the text of the string fields, see WithTrimSpace.
+	keepSpace bool
+	// the date system overriding the one of the workbook, nil means the workbook's, see WithDate1904.
+	date1904 *bool
+	// the separators of the numbers stored as text, nil means the Go syntax, see WithLocale.
+	locale *Locale
+	// every column of the header must be mapped to a field, see WithStrict.
+	strict bool
+	// the limits of the sheet, 0 means not limited, see WithMaxRows, WithMaxColumns, WithMaxCellLength and WithMaxSize.
+	maxRows       int
+	maxColumns    int
+	maxCellLength int
+	maxSize       int64
+	// the number of the sheets read at the same time by ReadWorkbook, see WithConcurrency.
+	concurrency int
+	// the lock of the workbook shared by the sheets of a call, the calls into the workbook other than
+	// the row iterators hold it, as excelize loads the worksheets and the styles without a lock.
+	fileMu *sync.Mutex
+}
+
+// the text of the bool values recognized by default, they are compared case-insensitively.
+// Excel stores the TRUE and FALSE of a boolean cell as 1 and 0.
+var defaultBoolValues = map[string]bool{
+	"TRUE": true, "T": true, "YES": true, "Y": true, "1": true, "是": true, "对": true, "真": true, "√": true, "✓": true, "✔": true,
+	"FALSE": false, "F": false, "NO": false, "N": false, "0": false, "否": false, "错": false, "假": false, "×": false, "✗": false, "✘": false,
+}
+
+// build the configuration from the options
+func newConfig(opts []Option) *config {
+	cfg := &config{fileMu: new(sync.Mutex)}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cfg)
+		}
+	}
+	if cfg.logger == nil {
+		cfg.logger = discardLogger
+	}
+	return cfg
+}
+
+// Keep reading after a row failed to decode instead of aborting on the first bad row.
+// The rows decoded successfully are returned together with a RowErrors of every bad cell.
+func WithCollectErrors() Option {
+	return func(c *config) {
+		c.collectErrors = true
+	}
+}
+
+// Use the conversions registered in the converters for reading and writing, see Converters.
+func WithConverters(converters *Converters) Option {
+	return func(c *config) {
+		c.converters = converters
+	}
+}
+
+// The sheet has no header, every row is data. The fields are mapped to the columns
+// by the col or idx option of the tag, such as x-read:"col=C" or x-read:"idx=2".
+func WithNoHeader() Option {
+	return func(c *config) {
+		c.noHeader = true
+	}
+}
+
+// Read the header from the row n, the row number starts from 1.
+// By default the first non-empty row is the header.
+func WithHeaderRow(n int) Option {
+	return func(c *config) {
+		c.headerRow = n
+	}
+}
+
+// The header spans n rows from the header row, such as a merged cell 人口 above the cells 男 and 女.
+// The columns are named by the paths joined by '/', and matched by the tags like x-read:"人口/男".
+func WithHeaderRows(n int) Option {
+	return func(c *config) {
+		c.headerRows = n
+	}
+}
+
+// Detect the header in the first scanRows rows, the row matching the most x-read aliases is the header.
+// It is useful for the sheets with the title rows and notes before the header, scanRows <= 0 means 20 rows.
+func WithHeaderDetection(scanRows int) Option {
+	return func(c *config) {
+		c.detectHeader = true
+		c.headerScanRows = scanRows
+	}
+}
+
+// Read the data from the row start to the row end, both inclusive and start from 1.
+// By default the data starts after the header, and end <= 0 means reading to the last row.
+func WithDataRows(start, end int) Option {
+	return func(c *config) {
+		c.dataStartRow = start
+		c.dataEndRow = end
+	}
+}
+
+// Stop reading at the row whose first non-empty cell is the marker, such as 合计 or 备注.
+// The row of the marker is not read.
+func WithStopMarker(marker string) Option {
+	return func(c *config) {
+		c.stopMarker = strings.TrimSpace(marker)
+	}
+}
+
+// Normalize the column names of the header and the aliases of the x-read tag by the rules before matching them,
+// such as WithHeaderNormalization(excel.NormalizeAll) matches the header "Phone Code" by x-read:"phone_code".
+// By default the column names are only trimmed.
+func WithHeaderNormalization(n Normalization) Option {
+	return func(c *config) {
+		c.normalization = n
+	}
+}
+
+// Recognize the bool values by the text, the values are compared case-insensitively and replace the default ones:
+// TRUE/FALSE, T/F, YES/NO, Y/N, 1/0, 是/否, 对/错, 真/假, √/×, ✓/✗ and ✔/✘. A value out of the vocabulary is an error.
+// The boolean cells of Excel are read whatever the vocabulary is.
+func WithBoolValues(trueValues, falseValues []string) Option {
+	return func(c *config) {
+		c.boolValues = make(map[string]bool, len(trueValues)+len(falseValues))
+		for _, value := range trueValues {
+			c.boolValues[strings.ToUpper(strings.TrimSpace(value))] = true
+		}
+		for _, value := range falseValues {
+			c.boolValues[strings.ToUpper(strings.TrimSpace(value))] = false
+		}
+	}
+}
+
+// the bool value of the text, ok is false if the text is not in the vocabulary
+func (c *config) boolValue(text string) (value bool, ok bool) {
+	values := c.boolValues
+	if values == nil {
+		values = defaultBoolValues
+	}
+	value, ok = values[strings.ToUpper(strings.TrimSpace(text))]
+	return value, ok
+}
+
+// Read the serial numbers of the dates and the dates as text without a time zone in the location,
+// and write the dates by the clock in the location. By default the location is UTC.
+func WithLocation(loc *time.Location) Option {
+	return func(c *config) {
+		c.location = loc
+	}
+}
+
+// the location of the dates without a time zone
+func (c *config) timeLocation() *time.Location {
+	if c.location == nil {
+		return time.UTC
+	}
+	return c.location
+}
+
+// Trim the spaces around the text of the string fields, it is true by default.
+// The numbers, the dates and the column names of the header are trimmed anyway.
+func WithTrimSpace(trim bool) Option {
+	return func(c *config) {
+		c.keepSpace = !trim
+	}
+}
+
+// Read the serial numbers of the dates in the 1904 date system if date1904 is true, otherwise in the 1900 date system,
+// whatever the date system of the workbook is. It is useful for the workbooks converted without the date system.
+func WithDate1904(date1904 bool) Option {
+	return func(c *config) {
+		c.date1904 = &date1904
+	}
+}
+
+// Locale is the separators of the numbers stored as text, such as 1.234,5 in German
+//
+//	excel.WithLocale(excel.Locale{DecimalSeparator: ',', GroupSeparator: '.'})
+type Locale struct {
+	DecimalSeparator rune
+	GroupSeparator   rune
+}
+
+// Read the numbers stored as text by the separators of the locale, such as 1,234.5 or 1.234,5.
+// The numeric cells don't depend on the locale, and the type of the cells is looked up in the workbook,
+// which loads the whole worksheet in memory.
+func WithLocale(locale Locale) Option {
+	return func(c *config) {
+		c.locale = &locale
+	}
+}
+
+// Every named column of the header must be mapped to a field, so that a renamed or an unexpected column
+// is an error instead of being ignored.
+func WithStrict() Option {
+	return func(c *config) {
+		c.strict = true
+	}
+}
+
+// Read at most n data rows, a sheet with more data rows is an ErrTooManyRows
+func WithMaxRows(n int) Option {
+	return func(c *config) {
+		c.maxRows = n
+	}
+}
+
+// A row with more than n columns is an ErrTooManyColumns, the empty cells at the end of the row are not counted
+func WithMaxColumns(n int) Option {
+	return func(c *config) {
+		c.maxColumns = n
+	}
+}
+
+// A cell longer than n bytes is an ErrCellTooLong
+func WithMaxCellLength(n int) Option {
+	return func(c *config) {
+		c.maxCellLength = n
+	}
+}
+
+// A workbook larger than n bytes when uncompressed is an ErrFileTooLarge. It is checked when the workbook is opened
+// by the package, such as ReadFromSheet and ReadFromReader, the workbook opened by the caller is not checked.
+func WithMaxSize(n int64) Option {
+	return func(c *config) {
+		c.maxSize = n
+	}
+}
+
+// Read at most n sheets at the same time in ReadWorkbook, the sheets share the opened workbook.
+// The results and the errors are in the order of the fields whatever the order the sheets are read in.
+// The merged cells, the types and the number formats of the cells are looked up in the workbook one at a time.
+// By default the sheets are read one by one.
+func WithConcurrency(n int) Option {
+	return func(c *config) {
+		c.concurrency = n
+	}
+}
+
+// Log the header located, the fields mapped and the rows skipped to the logger, such as slog.Default().
+// By default nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+// The number as text in the locale is converted to the Go syntax, such as 1.234,5 to 1234.5.
+// The numeric cells are stored in the Go syntax already, only the text cells are converted.
+func (c *config) numberText(cell Cell) string {
+	if c.locale == nil {
+		return cell.Value
+	}
+	cellType, err := cell.Type()
+	if err != nil || (cellType != excelize.CellTypeSharedString && cellType != excelize.CellTypeInlineString &&
+		cellType != excelize.CellTypeFormula) {
+		return cell.Value
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == c.locale.GroupSeparator || unicode.IsSpace(r):
+			return -1
+		case r == c.locale.DecimalSeparator:
+			return '.'
+		}
+		return r
+	}, cell.Value)
+}
+
+// the logger discarding every record
+var discardLogger = slog.New(discardHandler{})
+
+// discardHandler is a slog.Handler discarding every record
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
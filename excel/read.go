@@ -1,271 +1,343 @@
 package excel
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"reflect"
-	"strconv"
+	"sort"
 	"strings"
 
-	log "github.com/sirupsen/logrus"
 	"github.com/xuri/excelize/v2"
 )
 
-func init() {
-	log.SetLevel(log.FatalLevel)
+// Read the data from the sheet
+func ReadFromSheet[T any](filepath string, sheetName string, opts ...Option) ([]T, error) {
+	return ReadFromSheetContext[T](context.Background(), filepath, sheetName, opts...)
 }
 
-// Read the data from the sheet
-func ReadFromSheet[T any](filepath string, sheetName string) ([]T, error) {
-	f, err := excelize.OpenFile(filepath)
+// Read the data from the sheet, the reading is aborted with the error of the ctx when the ctx is done
+func ReadFromSheetContext[T any](ctx context.Context, filepath string, sheetName string, opts ...Option) (results []T, err error) {
+	f, err := openFile(filepath, newConfig(opts))
 	if err != nil {
-		return nil, fmt.Errorf("file opening failed. %s\n", filepath)
+		return nil, err
 	}
-	defer func() {
-		log.Tracef("the defer function fired, the xlsx file will be closed")
-		if err = f.Close(); err != nil {
-			log.Fatalf("there is a mistake when file close.")
-		}
-	}()
-	rows, err := f.GetRows(sheetName)
+	defer closeFile(f, &err)
+	return ReadFromFileContext[T](ctx, f, sheetName, opts...)
+}
+
+// Read the data from the sheet of the workbook read from r, such as an uploaded file
+func ReadFromReader[T any](r io.Reader, sheetName string, opts ...Option) ([]T, error) {
+	return ReadFromReaderContext[T](context.Background(), r, sheetName, opts...)
+}
+
+// Read the data from the sheet of the workbook read from r, the reading is aborted with the error of the ctx when the ctx is done
+func ReadFromReaderContext[T any](ctx context.Context, r io.Reader, sheetName string, opts ...Option) (results []T, err error) {
+	f, err := openReader(r, newConfig(opts))
 	if err != nil {
-		return nil, fmt.Errorf("No sheet with the specified name exists.")
+		return nil, err
 	}
-	if len(rows) <= 1 {
-		return nil, fmt.Errorf("No data in the sheet.")
+	defer closeFile(f, &err)
+	return ReadFromFileContext[T](ctx, f, sheetName, opts...)
+}
+
+// Read the data from the sheet of the workbook held in data
+func ReadFromBytes[T any](data []byte, sheetName string, opts ...Option) ([]T, error) {
+	return ReadFromReader[T](bytes.NewReader(data), sheetName, opts...)
+}
+
+// Read the data from the sheet of the workbook named name in fsys, such as an embed.FS
+func ReadFromFS[T any](fsys fs.FS, name string, sheetName string, opts ...Option) (results []T, err error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("file opening failed. %s: %w", name, err)
 	}
-	numberFormatIsUpdated := false
-	dataRowCount := 0
-	fieldMapping := make(map[string]*FieldMappingItem, 0)
-	style, _ := f.NewStyle(&excelize.Style{NumFmt: 1})
+	defer closeFile(file, &err)
+	return ReadFromReader[T](file, sheetName, opts...)
+}
+
+// Read the data from the sheet of an opened workbook, the workbook is not closed
+func ReadFromFile[T any](f *excelize.File, sheetName string, opts ...Option) ([]T, error) {
+	return ReadFromFileContext[T](context.Background(), f, sheetName, opts...)
+}
+
+// Read the data from the sheet of an opened workbook, the reading is aborted with the error of the ctx when the ctx is done
+func ReadFromFileContext[T any](ctx context.Context, f *excelize.File, sheetName string, opts ...Option) ([]T, error) {
+	results, err := readSheet(ctx, f, sheetName, reflect.TypeOf(new(T)).Elem(), newConfig(opts))
+	if !results.IsValid() {
+		return nil, err
+	}
+	return results.Interface().([]T), err
+}
 
-	// read the first row, init fieldMapping
-	for idx, row := range rows {
-		if len(row) == 0 {
-			// skip the black rows
+// close the file opened by the package, the error of closing is joined to the err
+func closeFile(f io.Closer, err *error) {
+	if closeErr := f.Close(); closeErr != nil {
+		*err = errors.Join(*err, fmt.Errorf("failed to close the file: %w", closeErr))
+	}
+}
+
+// Read the data from the sheet to a slice, the t is the type of the slice element.
+// With the collectErrors option, the decoded rows are returned together with the RowErrors.
+func readSheet(ctx context.Context, f *excelize.File, sheetName string, t reflect.Type, cfg *config) (reflect.Value, error) {
+	sr, err := newSheetReader(ctx, f, sheetName, t, cfg)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	defer sr.close()
+
+	results := reflect.MakeSlice(reflect.SliceOf(t), 0, 0)
+	var rowErrs RowErrors
+	for sr.next() {
+		item := reflect.New(t)
+		if err = sr.scan(item); err != nil {
+			var errs RowErrors
+			if !cfg.collectErrors || !errors.As(err, &errs) {
+				return reflect.Value{}, err
+			}
+			cfg.logger.Warn("excel: skip the row failed to decode", "sheet", sheetName, "row", sr.rowNum, "err", err)
+			rowErrs = append(rowErrs, errs...)
 			continue
 		}
-		colNameMappingIndex, err := initColNameMappingIndex(row)
-		if err != nil {
-			return nil, err
-		}
+		results = reflect.Append(results, item.Elem())
+	}
+	if sr.err != nil {
+		return reflect.Value{}, sr.err
+	}
+	cfg.logger.Debug("excel: sheet read", "sheet", sheetName, "rows", results.Len(), "errors", len(rowErrs))
+	if len(rowErrs) > 0 {
+		return results, rowErrs
+	}
+	return results, nil
+}
 
-		item := new(T)
-		t := reflect.TypeOf(item)
-		t = t.Elem()
-		fieldNum := t.NumField()
-		for i := 0; i < fieldNum; i++ {
-			fieldIndexSetted := false
-			fieldName := t.Field(i).Name
-			fieldTags := strings.Split(t.Field(i).Tag.Get(readTag), ",")
-			fieldType := t.Field(i).Type
-			for key := range colNameMappingIndex {
-				if containsInArray(key, fieldTags) {
-					fieldMapping[fieldName] = &FieldMappingItem{
-						FieldName: fieldName,
-						FieldType: fieldType,
-						ColIndex:  colNameMappingIndex[key],
-						ColName:   key,
-					}
-					fieldIndexSetted = true
-					delete(colNameMappingIndex, key)
-					break
-				}
+// Initialize the mapping between the fields of the schema and the columns of the header row,
+// the header is nil if the sheet has no header. The items are sorted by the column index
+func initFieldMapping(header []string, s *schema, cfg *config) ([]*FieldMappingItem, error) {
+	h, err := newHeaderIndex(header, cfg.normalization, false)
+	if err != nil {
+		return nil, err
+	}
+	fieldMapping := make([]*FieldMappingItem, 0, len(s.readFields))
+	var catchAll []structField
+	for _, field := range s.readFields {
+		tag := field.tag
+		if tag.isCatchAll() {
+			// the map field captures the columns left after the other fields are mapped
+			catchAll = append(catchAll, field)
+			continue
+		}
+		if field.Type.Kind() == reflect.Slice && tag.hasRegexAlias() {
+			// the slice field collects every column matching the aliases
+			colIndexes, err := h.findAll(tag)
+			if err != nil {
+				return nil, fmt.Errorf("field=%s, %w", field.path, err)
 			}
-			if !fieldIndexSetted {
-				return nil, fmt.Errorf("The field=%s not found in sheet header.", fieldName)
+			if len(colIndexes) == 0 && !tag.has(optionalOption) {
+				return nil, missingColumn(field, "is not found in the sheet header")
 			}
-			if fieldType.Kind() == reflect.Struct && fieldType.String() == "time.Time" {
-				columnIndexStr, _ := excelize.ColumnNumberToName(fieldMapping[fieldName].ColIndex + 1)
-				err := f.SetColStyle(sheetName, columnIndexStr, style)
-				if err != nil {
-					return nil, errors.New("set style for column failed. ")
-				}
-				numberFormatIsUpdated = true
+			fieldMapping = append(fieldMapping, newCollectItem(field, h, colIndexes))
+			continue
+		}
+		// the column selected by the col or idx option takes precedence over the header
+		if field.positional {
+			colName := h.name(field.colIndex)
+			if colName == "" && len(tag.aliases) > 0 {
+				colName = tag.aliases[0]
 			}
+			h.take(field.colIndex)
+			fieldMapping = append(fieldMapping, newFieldMappingItem(field, field.colIndex, colName))
+			continue
 		}
-		dataRowCount = len(rows) - idx - 1
-		break
-	}
-
-	if numberFormatIsUpdated {
-		// read the sheet again because the numberFormat is true
-		rows, err = f.GetRows(sheetName)
+		// the aliases are matched in the order of the tag
+		colIndex, found, err := h.find(tag)
 		if err != nil {
-			return nil, fmt.Errorf("can't find the sheet with the sheetName = %s\n", sheetName)
+			return nil, fmt.Errorf("field=%s, %w", field.path, err)
 		}
-	}
-	results := make([]T, 0, dataRowCount)
-
-	// reade the data
-	headerHasNotBeenRead := true
-	for _, row := range rows {
-		if len(row) == 0 {
-			// skip the black rows
+		if found {
+			fieldMapping = append(fieldMapping, newFieldMappingItem(field, colIndex, h.name(colIndex)))
+			h.take(colIndex)
 			continue
 		}
-		if headerHasNotBeenRead {
-			headerHasNotBeenRead = false
-		} else {
-			item := new(T)
-			v := reflect.ValueOf(item)
-			err = setDataForObject(v, row, fieldMapping)
-			if err != nil {
-				return nil, err
+		if !tag.has(optionalOption) {
+			if header == nil {
+				return nil, missingColumn(field, "has no col or idx option, it can't be mapped without the header")
 			}
-			results = append(results, *item)
+			return nil, missingColumn(field, "is not found in the sheet header")
+		}
+		// the optional field is not mapped to a column, it gets the default value if any
+		fieldMapping = append(fieldMapping, newFieldMappingItem(field, -1, ""))
+	}
+	for _, field := range catchAll {
+		if field.Type.Kind() != reflect.Map || field.Type.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("field=%s, the field tagged with %s should be a map with the string keys", field.path, catchAllAlias)
 		}
+		fieldMapping = append(fieldMapping, newCollectItem(field, h, h.rest()))
+	}
+	if cfg.strict {
+		if rest := h.rest(); len(rest) > 0 {
+			return nil, &HeaderError{Column: h.name(rest[0]), Err: fmt.Errorf("%w: The column is not mapped to any field.", ErrUnmappedColumn)}
+		}
+	}
+	sort.SliceStable(fieldMapping, func(i, j int) bool {
+		return fieldMapping[i].ColIndex < fieldMapping[j].ColIndex
+	})
+	return fieldMapping, nil
+}
+
+// the error of the required field not mapped to any column
+func missingColumn(field structField, reason string) error {
+	return &HeaderError{Field: field.path, Err: fmt.Errorf("%w: the field %s.", ErrMissingColumn, reason)}
+}
 
+// the item of the field collecting the columns, the columns are marked as mapped
+func newCollectItem(field structField, h *headerIndex, colIndexes []int) *FieldMappingItem {
+	item := newFieldMappingItem(field, -1, "")
+	item.ColIndexes = colIndexes
+	item.ColNames = make([]string, len(colIndexes))
+	item.collect = true
+	for i, colIndex := range colIndexes {
+		item.ColNames[i] = h.name(colIndex)
+		h.take(colIndex)
 	}
-	return results, nil
+	if len(colIndexes) > 0 {
+		item.ColIndex = colIndexes[0]
+	}
+	return item
 }
 
-// Set the object value for each row data
-func setDataForObject(item reflect.Value, cells []string, fieldMapping map[string]*FieldMappingItem) error {
+// Set the object value for each row data, every cell that failed to decode is returned
+func (sr *sheetReader) setDataForObject(item reflect.Value) []*CellError {
 	if item.Type().Kind() == reflect.Pointer {
 		item = item.Elem()
 	}
 
-	for k, v := range fieldMapping {
-		field := item.FieldByName(k)
-		kind := field.Type().Kind()
-		// if kind == reflect.Pointer {
-		// 	kind = field.Type().Elem().Kind()
-		// }
-		switch kind {
-		case reflect.String:
-			set2String(field, cells[v.ColIndex])
-		case reflect.Int, reflect.Int32, reflect.Int64, reflect.Int8, reflect.Int16:
-			err := set2Int64(field, cells[v.ColIndex])
-			if err != nil {
-				return fmt.Errorf("col=%s, %s", fieldMapping[k].ColName, err.Error())
-			}
-		case reflect.Float64, reflect.Float32:
-			err := set2float64(field, cells[v.ColIndex])
-			if err != nil {
-				return fmt.Errorf("col=%s, %s", fieldMapping[k].ColName, err.Error())
-			}
-		case reflect.Bool:
-			set2bool(field, cells[v.ColIndex])
-		case reflect.Struct:
-			if field.Type().String() == "time.Time" {
-				err := set2Time(field, cells[v.ColIndex])
-				if err != nil {
-					return fmt.Errorf("col=%s, %s", fieldMapping[k].ColName, err.Error())
-				}
-			}
-		case reflect.Pointer:
-			return fmt.Errorf("A data field cannot defined as a pointer.")
+	var errs []*CellError
+	for _, v := range sr.fieldMapping {
+		field, _ := fieldByIndex(item, v.Index, true)
+		if v.collect {
+			errs = append(errs, sr.decodeColumns(field, v)...)
+			continue
+		}
+		if err := sr.decodeColumn(field, v, v.ColIndex, v.ColName); err != nil {
+			errs = append(errs, err)
 		}
-
 	}
-	return nil
+	return errs
 }
 
-// Initialize the mapping between the header column name and the index of the column where the column name must be unique
-func initColNameMappingIndex(cells []string) (map[string]int, error) {
-	colNameMappingIndex := make(map[string]int, len(cells))
-	for colIndex, cell := range cells {
-		_, ok := colNameMappingIndex[cell]
-		if ok {
-			return nil, fmt.Errorf("The same column name exists in the sheet.")
+// decode the cell of the column to the field, the colIndex is -1 if the optional column is absent
+func (sr *sheetReader) decodeColumn(field reflect.Value, item *FieldMappingItem, colIndex int, colName string) *CellError {
+	cell := Cell{
+		Sheet:    sr.sheetName,
+		Row:      sr.rowNum,
+		Col:      colIndex + 1,
+		Date1904: sr.date1904,
+		file:     sr.f,
+		mu:       sr.cfg.fileMu,
+	}
+	if colIndex >= 0 {
+		cell.Value = sr.cellValue(item, colIndex)
+		if sr.rows != nil {
+			cell.Text = getCell(sr.cells, colIndex)
 		}
-		colNameMappingIndex[cell] = colIndex
 	}
-	return colNameMappingIndex, nil
-}
-
-// Determines whether the tag in the field matches the header column name
-func containsInArray(key string, tags []string) bool {
-	key = strings.TrimSpace(key)
-	for _, s := range tags {
-		if strings.TrimSpace(s) == key {
-			return true
+	value, decode, err := applyEmptyCellOptions(cell.Value, item)
+	if decode {
+		cell.Value = value
+		if sep, ok := item.split(); ok {
+			err = decodeSplitCell(field, cell, sep, item, sr.cfg)
+		} else {
+			err = decodeCell(field, cell, item, sr.cfg)
+		}
+		if err != nil {
+			err = fmt.Errorf("%w: %w", ErrConversion, err)
 		}
 	}
-	return false
-}
-
-// set the cell value, usually is string, to a string field
-func set2String(value reflect.Value, str string) {
-	switch value.Type().Kind() {
-	case reflect.String:
-		value.SetString(strings.TrimSpace(str))
-	case reflect.Pointer:
-		s := strings.TrimSpace(str)
-		value.Set(reflect.ValueOf(&s))
+	if err == nil {
+		return nil
 	}
-}
-
-// set the cell value, usually is string, to a integer field
-func set2Int64(value reflect.Value, str string) error {
-	intValue, err := strconv.ParseInt(strings.TrimSpace(str), 0, 64)
-	if err != nil {
-		return fmt.Errorf("failed to convert value=%s to a int", str)
+	axis := ""
+	if colIndex >= 0 {
+		axis = cell.Axis()
 	}
-	switch value.Type().Kind() {
-	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Int8, reflect.Int16:
-		value.SetInt(intValue)
-	case reflect.Pointer:
-		switch value.Type().Elem().Kind() {
-		case reflect.Int:
-			num := int(intValue)
-			value.Set(reflect.ValueOf(&num))
-		case reflect.Int32:
-			num := int32(intValue)
-			value.Set(reflect.ValueOf(&num))
-		case reflect.Int64:
-			value.Set(reflect.ValueOf(&intValue))
-		}
+	return &CellError{
+		Sheet:  sr.sheetName,
+		Cell:   axis,
+		Row:    sr.rowNum,
+		Column: colName,
+		Field:  item.FieldName,
+		Value:  sr.cellValue(item, colIndex),
+		Err:    err,
 	}
-	return nil
 }
 
-// set the cell value, usually is string, to a float field
-func set2float64(value reflect.Value, str string) error {
-	floatValue, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
-	if err != nil {
-		return errors.New("failed to convert to a float")
+// Decode the columns collected by a slice or a map field, the elements are in the order of the columns.
+// The map is keyed by the column names, and the empty cells are left out unless the default or the required option is set.
+func (sr *sheetReader) decodeColumns(field reflect.Value, item *FieldMappingItem) []*CellError {
+	var errs []*CellError
+	if field.Kind() == reflect.Slice {
+		values := reflect.MakeSlice(field.Type(), len(item.ColIndexes), len(item.ColIndexes))
+		for i, colIndex := range item.ColIndexes {
+			if err := sr.decodeColumn(values.Index(i), item, colIndex, item.ColNames[i]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		field.Set(values)
+		return errs
 	}
-	switch value.Type().Kind() {
-	case reflect.Float64, reflect.Float32:
-		value.SetFloat(floatValue)
-	case reflect.Pointer:
-		value.Set(reflect.ValueOf(&floatValue))
+
+	values := reflect.MakeMapWithSize(field.Type(), len(item.ColIndexes))
+	keepEmpty := item.tag.has(defaultOption) || item.tag.has(requiredOption)
+	for i, colIndex := range item.ColIndexes {
+		if !keepEmpty && strings.TrimSpace(sr.cellValue(item, colIndex)) == "" {
+			continue
+		}
+		value := reflect.New(field.Type().Elem()).Elem()
+		if err := sr.decodeColumn(value, item, colIndex, item.ColNames[i]); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		values.SetMapIndex(reflect.ValueOf(item.ColNames[i]).Convert(field.Type().Key()), value)
 	}
-	return nil
+	field.Set(values)
+	return errs
 }
 
-// set the cell value, usually is string, to a time.Time field
-func set2Time(value reflect.Value, str string) error {
-	floatValue, err := strconv.ParseFloat(str, 64)
-	if err != nil {
-		return fmt.Errorf("failed to convert value=%s to a time", str)
+// apply the default, required and omitempty options to an empty cell, it returns the value to decode
+// and whether the value should be decoded, the field is left as the zero value if not
+func applyEmptyCellOptions(value string, item *FieldMappingItem) (string, bool, error) {
+	if strings.TrimSpace(value) != "" {
+		return value, true, nil
 	}
-	toTime, err := excelize.ExcelDateToTime(floatValue, false)
-	if err != nil {
-		return fmt.Errorf("failed to convert value=%s to a time", str)
+	if defaultValue, ok := item.tag.option(defaultOption); ok {
+		return defaultValue, true, nil
+	}
+	if item.tag.has(requiredOption) {
+		return value, false, errors.New("the cell is required but empty")
 	}
-	switch value.Type().Kind() {
-	case reflect.Struct:
-		value.Set(reflect.ValueOf(toTime))
-	case reflect.Pointer:
-		value.Set(reflect.ValueOf(&toTime))
+	if item.ColIndex < 0 || item.tag.has(omitemptyOption) {
+		return value, false, nil
 	}
-	return nil
+	return value, true, nil
 }
 
-// set the cell value, usually is string, to a bool field
-func set2bool(value reflect.Value, str string) {
-	s := strings.ToUpper(strings.TrimSpace(str))
-	v := true
-	if s == "FALSE" || s == "0" {
-		v = false
+// the value of the cell decoded to the field, the text fields read the cell as displayed by the number format,
+// such as 12.50% or 1/9/24 00:00, and the other fields read the raw value, such as 0.125 or 45300
+func (sr *sheetReader) cellValue(item *FieldMappingItem, colIndex int) string {
+	if item.raw && sr.rows != nil && sr.rawRows != nil {
+		return getCell(sr.raw, colIndex)
 	}
-	switch value.Type().Kind() {
-	case reflect.Bool:
-		value.SetBool(v)
-	case reflect.Pointer:
-		value.Set(reflect.ValueOf(&v))
+	return getCell(sr.cells, colIndex)
+}
+
+// the cell of the column, the empty cells at the end of a row are not returned by the row iterator
+func getCell(cells []string, colIndex int) string {
+	if colIndex >= 0 && colIndex < len(cells) {
+		return cells[colIndex]
 	}
+	return ""
 }
@@ -0,0 +1,396 @@
+package excel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Reader reads the data rows of a sheet one by one, only the current row is held in memory.
+//
+//	r, err := excel.OpenSheet[City]("cities.xlsx", "城市")
+//	if err != nil {
+//		return err
+//	}
+//	defer r.Close()
+//	for r.Next() {
+//		var c City
+//		if err := r.Scan(&c); err != nil {
+//			return err
+//		}
+//	}
+//	return r.Err()
+type Reader[T any] struct {
+	sr *sheetReader
+}
+
+// Create a Reader of the sheet of an opened workbook, the workbook is not closed by Reader.Close
+func NewReader[T any](f *excelize.File, sheetName string, opts ...Option) (*Reader[T], error) {
+	return NewReaderContext[T](context.Background(), f, sheetName, opts...)
+}
+
+// Create a Reader of the sheet of an opened workbook, see NewReader. The reading is aborted with the error
+// of the ctx when the ctx is done, such as the request of an upload is canceled
+func NewReaderContext[T any](ctx context.Context, f *excelize.File, sheetName string, opts ...Option) (*Reader[T], error) {
+	sr, err := newSheetReader(ctx, f, sheetName, reflect.TypeOf(new(T)).Elem(), newConfig(opts))
+	if err != nil {
+		return nil, err
+	}
+	return &Reader[T]{sr: sr}, nil
+}
+
+// Open the workbook and create a Reader of the sheet, the workbook is closed by Reader.Close
+func OpenSheet[T any](filepath string, sheetName string, opts ...Option) (*Reader[T], error) {
+	return OpenSheetContext[T](context.Background(), filepath, sheetName, opts...)
+}
+
+// Open the workbook and create a Reader of the sheet, see OpenSheet. The reading is aborted with the error
+// of the ctx when the ctx is done
+func OpenSheetContext[T any](ctx context.Context, filepath string, sheetName string, opts ...Option) (*Reader[T], error) {
+	f, err := openFile(filepath, newConfig(opts))
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReaderContext[T](ctx, f, sheetName, opts...)
+	if err != nil {
+		closeFile(f, &err)
+		return nil, err
+	}
+	r.sr.ownedFile = true
+	return r, nil
+}
+
+// Advance to the next data row, it returns false when there are no more rows or an error occurred
+func (r *Reader[T]) Next() bool {
+	return r.sr.next()
+}
+
+// Decode the current row to dest, the cells that failed to decode are returned as a RowErrors
+func (r *Reader[T]) Scan(dest *T) error {
+	if dest == nil {
+		return errors.New("the dest of Scan should not be nil")
+	}
+	// the fields not decoded from the row, such as the absent optional columns, don't keep the values of the last row
+	var zero T
+	*dest = zero
+	return r.sr.scan(reflect.ValueOf(dest))
+}
+
+// The error occurred during the iteration, if any
+func (r *Reader[T]) Err() error {
+	return r.sr.err
+}
+
+// The number of the current row in the sheet, starts from 1
+func (r *Reader[T]) RowNumber() int {
+	return r.sr.rowNum
+}
+
+// Release the row iterator, it can be called at any time to stop reading
+func (r *Reader[T]) Close() error {
+	return r.sr.close()
+}
+
+// sheetReader streams the rows of a sheet and decodes them to values of the type t
+type sheetReader struct {
+	// the reading is aborted when the ctx is done.
+	ctx       context.Context
+	f         *excelize.File
+	ownedFile bool
+	sheetName string
+	t         reflect.Type
+	schema    *schema
+	cfg       *config
+	// the iterator of the formatted text of the cells and the iterator of the raw values, they are advanced together.
+	// The header is read by the text, and the data rows are read by the views the mapped fields need, see openData.
+	rows    *excelize.Rows
+	rawRows *excelize.Rows
+	// the number of the last header row, 0 without the header.
+	headerEnd int
+	// the number of the last row read from the iterator, starts from 1.
+	iterRowNum int
+	// the rows read ahead when locating the header, they are returned before the rows of the iterator.
+	pending []sheetRow
+	// the number of the current row, starts from 1.
+	rowNum int
+	// the number of the data rows read, limited by WithMaxRows.
+	dataRows int
+	// the cells of the current row, see sheetRow.
+	cells        []string
+	raw          []string
+	fieldMapping []*FieldMappingItem
+	// whether the workbook uses the 1904 date system.
+	date1904 bool
+	// whether some rows before the data start row are skipped, the sheet is not empty then.
+	skipped bool
+	// whether the last data row is passed.
+	done bool
+	err  error
+}
+
+// sheetRow is a non-empty row of the sheet
+type sheetRow struct {
+	// the row number, starts from 1.
+	num int
+	// the formatted text of the cells, or the raw values if the text is not read.
+	cells []string
+	// the raw values of the cells if both the text and the raw values are read.
+	raw []string
+}
+
+// Create a sheetReader, the header row is located by the options
+func newSheetReader(ctx context.Context, f *excelize.File, sheetName string, t reflect.Type, cfg *config) (*sheetReader, error) {
+	s, err := schemaOf(t)
+	if err != nil {
+		return nil, err
+	}
+	sr := &sheetReader{ctx: ctx, f: f, sheetName: sheetName, t: t, schema: s, cfg: cfg}
+	// the serial number of the dates depends on the date system of the workbook
+	cfg.fileMu.Lock()
+	props, err := f.GetWorkbookProps()
+	if err == nil {
+		// the row iterators read the styles of the formatted cells without a lock, they are loaded in advance
+		_, _ = f.GetStyle(0)
+	}
+	cfg.fileMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if props.Date1904 != nil {
+		sr.date1904 = *props.Date1904
+	}
+	if cfg.date1904 != nil {
+		sr.date1904 = *cfg.date1904
+	}
+	var header []string
+	if !cfg.noHeader {
+		if sr.rows, err = sr.openRows(); err != nil {
+			return nil, err
+		}
+		if header, err = sr.readHeader(); err != nil {
+			sr.close()
+			return nil, err
+		}
+	}
+	fieldMapping, err := initFieldMapping(header, s, cfg)
+	if err != nil {
+		sr.close()
+		var headerErr *HeaderError
+		if errors.As(err, &headerErr) {
+			headerErr.Sheet = sheetName
+		}
+		return nil, err
+	}
+	for _, item := range fieldMapping {
+		if item.converter, err = cfg.converters.lookup(item); err != nil {
+			sr.close()
+			return nil, err
+		}
+		item.text, item.raw = cellViews(item)
+	}
+	for _, item := range fieldMapping {
+		cfg.logger.Debug("excel: field mapped", "sheet", sheetName, "field", item.FieldName, "column", item.ColName, "index", item.ColIndex)
+	}
+	sr.fieldMapping = fieldMapping
+	if err = sr.openData(); err != nil {
+		sr.close()
+		return nil, err
+	}
+	return sr, nil
+}
+
+// Open the iterators of the data rows by the views of the cells the mapped fields read, a single iterator is read
+// unless some fields read the formatted text and the others read the raw values. The iterator of the header goes on
+// if the fields read the text only, otherwise the sheet is read again from the first row and the header is skipped.
+func (sr *sheetReader) openData() (err error) {
+	var text, raw bool
+	for _, item := range sr.fieldMapping {
+		text, raw = text || item.text, raw || item.raw
+	}
+	if !raw && sr.rows != nil {
+		return nil
+	}
+	if sr.rows != nil {
+		err = sr.rows.Close()
+		sr.rows, sr.pending, sr.iterRowNum = nil, nil, 0
+		if err != nil {
+			return err
+		}
+	}
+	if text || !raw {
+		if sr.rows, err = sr.openRows(); err != nil {
+			return err
+		}
+	}
+	if raw {
+		sr.rawRows, err = sr.openRows()
+	}
+	return err
+}
+
+func (sr *sheetReader) openRows() (*excelize.Rows, error) {
+	sr.cfg.fileMu.Lock()
+	rows, err := sr.f.Rows(sr.sheetName)
+	sr.cfg.fileMu.Unlock()
+	if err != nil {
+		if errors.As(err, new(excelize.ErrSheetNotExist)) {
+			return nil, fmt.Errorf("%w: %w", ErrSheetNotFound, err)
+		}
+		return nil, fmt.Errorf("failed to read the sheet=%s: %w", sr.sheetName, err)
+	}
+	return rows, nil
+}
+
+// read the next non-empty row, the rows read ahead are returned first
+func (sr *sheetReader) readRow() (sheetRow, bool) {
+	if sr.err == nil && sr.ctx.Err() != nil {
+		sr.err = sr.ctx.Err()
+	}
+	if sr.err != nil {
+		return sheetRow{}, false
+	}
+	if len(sr.pending) > 0 {
+		row := sr.pending[0]
+		sr.pending = sr.pending[1:]
+		return row, true
+	}
+	for {
+		cells, raw, ok, err := sr.nextColumns()
+		if err != nil || !ok {
+			sr.err = err
+			return sheetRow{}, false
+		}
+		if err = sr.ctx.Err(); err != nil {
+			sr.err = err
+			return sheetRow{}, false
+		}
+		sr.iterRowNum++
+		if sr.rows == nil {
+			cells, raw = raw, nil
+		}
+		if len(cells) == 0 && len(raw) == 0 {
+			// skip the black rows
+			continue
+		}
+		if sr.err = sr.checkLimits(cells); sr.err != nil {
+			return sheetRow{}, false
+		}
+		return sheetRow{num: sr.iterRowNum, cells: cells, raw: raw}, true
+	}
+}
+
+// Advance the open iterators to the next row, ok is false at the end of the sheet. The header and the strings
+// read the cells as displayed by the number format, the numbers and the dates are decoded from the stored value
+func (sr *sheetReader) nextColumns() (cells, raw []string, ok bool, err error) {
+	if sr.rows == nil && sr.rawRows == nil {
+		return nil, nil, false, nil
+	}
+	if sr.rows != nil {
+		if !sr.rows.Next() {
+			return nil, nil, false, sr.rows.Error()
+		}
+		if cells, err = sr.rows.Columns(); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	if sr.rawRows != nil {
+		if !sr.rawRows.Next() {
+			return nil, nil, false, sr.rawRows.Error()
+		}
+		if raw, err = sr.rawRows.Columns(excelize.Options{RawCellValue: true}); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	return cells, raw, true, nil
+}
+
+// advance to the next data row, the rows before the data start row are skipped,
+// and the reading stops after the data end row or at the stop marker
+func (sr *sheetReader) next() bool {
+	sr.cells, sr.raw = nil, nil
+	if sr.done {
+		return false
+	}
+	for {
+		row, ok := sr.readRow()
+		if !ok {
+			if sr.err == nil && sr.dataRows == 0 && !sr.skipped {
+				// the sheet ends without any data row, such as a sheet with the header only
+				sr.err = fmt.Errorf("%w: No data in the sheet.", ErrEmptySheet)
+			}
+			sr.done = true
+			return false
+		}
+		if row.num <= sr.headerEnd {
+			// the header rows read again by the iterators of the data
+			continue
+		}
+		if row.num < sr.cfg.dataStartRow {
+			sr.skipped = true
+			continue
+		}
+		if (sr.cfg.dataEndRow > 0 && row.num > sr.cfg.dataEndRow) || isStopRow(row.cells, sr.cfg.stopMarker) {
+			sr.done = true
+			return false
+		}
+		sr.dataRows++
+		if sr.cfg.maxRows > 0 && sr.dataRows > sr.cfg.maxRows {
+			sr.err = fmt.Errorf("%w: the sheet has more than %d data rows", ErrTooManyRows, sr.cfg.maxRows)
+			sr.done = true
+			return false
+		}
+		sr.rowNum, sr.cells, sr.raw = row.num, row.cells, row.raw
+		return true
+	}
+}
+
+// check the row read from the iterator by WithMaxColumns and WithMaxCellLength
+func (sr *sheetReader) checkLimits(cells []string) error {
+	if sr.cfg.maxColumns > 0 && len(cells) > sr.cfg.maxColumns {
+		return fmt.Errorf("%w: the row %d has %d columns, more than %d", ErrTooManyColumns, sr.iterRowNum, len(cells), sr.cfg.maxColumns)
+	}
+	if sr.cfg.maxCellLength > 0 {
+		for colIndex, cell := range cells {
+			if len(cell) > sr.cfg.maxCellLength {
+				axis, _ := excelize.CoordinatesToCellName(colIndex+1, sr.iterRowNum)
+				return fmt.Errorf("%w: the cell %s is longer than %d bytes", ErrCellTooLong, axis, sr.cfg.maxCellLength)
+			}
+		}
+	}
+	return nil
+}
+
+// decode the current row to the item, the item is a pointer to a value of the type t.
+// the cells that failed to decode are returned as a RowErrors
+func (sr *sheetReader) scan(item reflect.Value) error {
+	if sr.cells == nil {
+		return errors.New("Scan called without a successful Next")
+	}
+	if errs := sr.setDataForObject(item); len(errs) > 0 {
+		return RowErrors(errs)
+	}
+	return nil
+}
+
+// close the row iterator, and the workbook if it is opened by the reader
+func (sr *sheetReader) close() error {
+	if sr.rows == nil && sr.rawRows == nil {
+		return nil
+	}
+	var err error
+	if sr.rows != nil {
+		err = sr.rows.Close()
+		sr.rows = nil
+	}
+	if sr.rawRows != nil {
+		err = errors.Join(err, sr.rawRows.Close())
+		sr.rawRows = nil
+	}
+	if sr.ownedFile {
+		closeFile(sr.f, &err)
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package excel
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Validate the tags of the struct T, such as the col and idx options and the regular expressions of the aliases.
+// The mapping of T compiled from the tags is cached for every reading and writing of T, so calling it at startup
+// reports the invalid tags before any sheet is read and saves the compiling from the first call.
+func CompileSchema[T any]() error {
+	_, err := schemaOf(reflect.TypeOf((*T)(nil)).Elem())
+	return err
+}
+
+// schema is the compiled mapping of a struct type
+type schema struct {
+	t reflect.Type
+	// the flattened fields with the x-read tags.
+	readFields []structField
+	// the flattened fields with the x-write tags, the fields without a column name are not written.
+	writeFields []structField
+}
+
+// the compiled schemas by the struct type
+var schemas sync.Map
+
+// the schema of the struct type t, it is compiled on the first use
+func schemaOf(t reflect.Type) (*schema, error) {
+	if s, ok := schemas.Load(t); ok {
+		return s.(*schema), nil
+	}
+	s, err := compileSchema(t)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := schemas.LoadOrStore(t, s)
+	return actual.(*schema), nil
+}
+
+// compile the tags of the fields, the col and idx options and the regular expressions are checked here
+func compileSchema(t reflect.Type) (*schema, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("the type should be a struct, the current type is %s", t.String())
+	}
+	s := &schema{
+		t:          t,
+		readFields: structFields(t, getReadTag),
+	}
+	for i := range s.readFields {
+		field := &s.readFields[i]
+		var err error
+		if field.colIndex, field.positional, err = field.tag.position(); err != nil {
+			return nil, fmt.Errorf("field=%s, %w", field.path, err)
+		}
+		if err = field.tag.compile(); err != nil {
+			return nil, fmt.Errorf("field=%s, %w", field.path, err)
+		}
+	}
+	for _, field := range structFields(t, getWriteTag) {
+		if len(field.tag.aliases) > 0 {
+			s.writeFields = append(s.writeFields, field)
+		}
+	}
+	return s, nil
+}
@@ -1,7 +1,181 @@
 package excel
 
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
 const (
 	readTag  string = "x-read"
 	writeTag string = "x-write"
 	sheetTag string = "x-sheet"
 )
+
+// the keys of the tag options, the options follow the column names and are separated by ';'
+//
+//	x-read:"时长,用时;conv=duration"
+//	x-read:"电话区号;optional;default=0"
+const (
+	// use the converter registered by the name.
+	convOption string = "conv"
+	// the column may be absent from the header, the field is left as the zero value or the default.
+	optionalOption string = "optional"
+	// the value decoded when the cell is empty or the optional column is absent.
+	defaultOption string = "default"
+	// an empty cell is an error.
+	requiredOption string = "required"
+	// an empty cell leaves the field as the zero value instead of being decoded,
+	// and the zero value is written as an empty cell.
+	omitemptyOption string = "omitempty"
+	// the field is mapped to the column by the column name such as C, instead of the header.
+	colOption string = "col"
+	// the field is mapped to the column by the index starting from 0, instead of the header.
+	idxOption string = "idx"
+	// the fields of the nested struct are mapped to the columns named with the prefix, such as x-read:"prefix=收件人".
+	prefixOption string = "prefix"
+	// the cell holds several values separated by the separator, such as x-read:"城市;split=," for 南京,苏州,无锡.
+	// the field is a slice, and the values are joined by the separator on writing.
+	splitOption string = "split"
+	// the layout of the date as text, such as x-read:"日期;layout=2006/01/02", it formats the date on writing too.
+	layoutOption string = "layout"
+)
+
+// the prefix of an alias matching the column names by the regular expression, such as x-read:"re:^电话.*区号$".
+// the regular expression is the rest of the column names, so it is the last alias and may contain ','.
+const regexAliasPrefix string = "re:"
+
+// the alias of a map field capturing every column not mapped by the other fields, such as x-read:"*"
+const catchAllAlias string = "*"
+
+// the options with a value that can be written without the column names, such as x-read:"col=C"
+var leadingOptions = map[string]bool{
+	convOption:    true,
+	defaultOption: true,
+	colOption:     true,
+	idxOption:     true,
+	prefixOption:  true,
+	layoutOption:  true,
+}
+
+// fieldTag is the parsed x-read or x-write tag of a field
+type fieldTag struct {
+	// the possible column names of the field.
+	aliases []string
+	// the options of the field, the value of a flag option is empty.
+	options map[string]string
+	// the compiled regular expressions of the aliases, see compile.
+	patterns map[string]*regexp.Regexp
+}
+
+// parse the tag as `name1,name2;key=value;flag`, the column names can be omitted before an option with a value
+func parseFieldTag(tag string) *fieldTag {
+	parts := strings.Split(tag, ";")
+	ft := &fieldTag{options: make(map[string]string)}
+	if key, _, ok := strings.Cut(parts[0], "="); !ok || !leadingOptions[strings.TrimSpace(key)] {
+		names := parts[0]
+		for names != "" {
+			var alias string
+			if strings.HasPrefix(strings.TrimSpace(names), regexAliasPrefix) {
+				alias, names = names, ""
+			} else {
+				alias, names, _ = strings.Cut(names, ",")
+			}
+			if alias = strings.TrimSpace(alias); alias != "" {
+				ft.aliases = append(ft.aliases, alias)
+			}
+		}
+		parts = parts[1:]
+	}
+	for _, part := range parts {
+		key, value, _ := strings.Cut(part, "=")
+		if key = strings.TrimSpace(key); key != "" {
+			ft.options[key] = value
+		}
+	}
+	return ft
+}
+
+// the x-read tag of the field
+func getReadTag(field reflect.StructField) *fieldTag {
+	return parseFieldTag(field.Tag.Get(readTag))
+}
+
+// compile the regular expressions of the aliases, such as re:^电话.*区号$
+func (ft *fieldTag) compile() error {
+	for _, alias := range ft.aliases {
+		if pattern, ok := strings.CutPrefix(alias, regexAliasPrefix); ok {
+			re, err := regexp.Compile(strings.TrimSpace(pattern))
+			if err != nil {
+				return fmt.Errorf("the alias %s is not a valid regular expression: %w", alias, err)
+			}
+			if ft.patterns == nil {
+				ft.patterns = make(map[string]*regexp.Regexp)
+			}
+			ft.patterns[alias] = re
+		}
+	}
+	return nil
+}
+
+// the compiled regular expression of the alias, it is compiled now if the tag is not compiled
+func (ft *fieldTag) pattern(alias string) (*regexp.Regexp, error) {
+	if re, ok := ft.patterns[alias]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(strings.TrimSpace(strings.TrimPrefix(alias, regexAliasPrefix)))
+	if err != nil {
+		return nil, fmt.Errorf("the alias %s is not a valid regular expression: %w", alias, err)
+	}
+	return re, nil
+}
+
+// whether any alias is a regular expression
+func (ft *fieldTag) hasRegexAlias() bool {
+	for _, alias := range ft.aliases {
+		if strings.HasPrefix(alias, regexAliasPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// whether the tag captures every column not mapped by the other fields
+func (ft *fieldTag) isCatchAll() bool {
+	return len(ft.aliases) == 1 && ft.aliases[0] == catchAllAlias
+}
+
+// the value of the option, ok is false when the option is not set
+func (ft *fieldTag) option(key string) (string, bool) {
+	value, ok := ft.options[key]
+	return value, ok
+}
+
+// whether the flag option is set
+func (ft *fieldTag) has(key string) bool {
+	_, ok := ft.options[key]
+	return ok
+}
+
+// the column index set by the col or idx option, ok is false if neither is set
+func (ft *fieldTag) position() (colIndex int, ok bool, err error) {
+	if col, ok := ft.option(colOption); ok {
+		colNum, err := excelize.ColumnNameToNumber(strings.TrimSpace(col))
+		if err != nil {
+			return -1, true, fmt.Errorf("the col=%s is not a valid column name: %w", col, err)
+		}
+		return colNum - 1, true, nil
+	}
+	if idx, ok := ft.option(idxOption); ok {
+		colIndex, err := strconv.Atoi(strings.TrimSpace(idx))
+		if err != nil || colIndex < 0 {
+			return -1, true, fmt.Errorf("the idx=%s is not a valid column index", idx)
+		}
+		return colIndex, true, nil
+	}
+	return -1, false, nil
+}
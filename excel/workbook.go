@@ -0,0 +1,224 @@
+package excel
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// SheetError is the error of reading a sheet to a slice field of the workbook struct.
+type SheetError struct {
+	// the field name of the workbook struct.
+	FieldName string
+	// the sheet name selected by the x-sheet tag, empty if no sheet was selected.
+	SheetName string
+	// the error occurred when reading the sheet.
+	Err error
+}
+
+func (e *SheetError) Error() string {
+	if e.SheetName == "" {
+		return fmt.Sprintf("field=%s: %s", e.FieldName, e.Err.Error())
+	}
+	return fmt.Sprintf("sheet=%s, field=%s: %s", e.SheetName, e.FieldName, e.Err.Error())
+}
+
+func (e *SheetError) Unwrap() error {
+	return e.Err
+}
+
+// WorkbookError is the report of the sheets that failed to read, in the order of the fields.
+type WorkbookError struct {
+	Sheets []*SheetError
+}
+
+func (e *WorkbookError) Error() string {
+	msgs := make([]string, len(e.Sheets))
+	for i, sheetErr := range e.Sheets {
+		msgs[i] = sheetErr.Error()
+	}
+	return fmt.Sprintf("%d sheet(s) failed to read: %s", len(e.Sheets), strings.Join(msgs, "; "))
+}
+
+func (e *WorkbookError) Unwrap() []error {
+	errs := make([]error, len(e.Sheets))
+	for i, sheetErr := range e.Sheets {
+		errs[i] = sheetErr
+	}
+	return errs
+}
+
+// Read the workbook to a struct, each slice field of the struct holds the data of a sheet.
+// The sheet of a field is selected by the x-sheet tag:
+//
+//	x-sheet:"城市"      the sheet named 城市
+//	x-sheet:"[]"        the first sheet
+//	x-sheet:"[2]"       the sheet with the index 2, the index starts from 0
+//	x-sheet:"re:^2023"  the first sheet whose name matches the regular expression
+//
+// The field name is used as the sheet name when the tag is absent, and x-sheet:"-" skips the field.
+// The sheets that failed to read are reported by a *WorkbookError, the other fields are still filled.
+// The options are applied to every sheet.
+func ReadWorkbook[T any](filepath string, opts ...Option) (*T, error) {
+	return ReadWorkbookContext[T](context.Background(), filepath, opts...)
+}
+
+// Read the workbook to a struct, see ReadWorkbook. The reading is aborted with the error of the ctx when the ctx is done
+func ReadWorkbookContext[T any](ctx context.Context, filepath string, opts ...Option) (result *T, err error) {
+	f, err := openFile(filepath, newConfig(opts))
+	if err != nil {
+		return nil, err
+	}
+	defer closeFile(f, &err)
+	return ReadWorkbookFromFileContext[T](ctx, f, opts...)
+}
+
+// Read the workbook read from r to a struct, see ReadWorkbook
+func ReadWorkbookFromReader[T any](r io.Reader, opts ...Option) (*T, error) {
+	return ReadWorkbookFromReaderContext[T](context.Background(), r, opts...)
+}
+
+// Read the workbook read from r to a struct, see ReadWorkbook. The reading is aborted with the error of the ctx when the ctx is done
+func ReadWorkbookFromReaderContext[T any](ctx context.Context, r io.Reader, opts ...Option) (result *T, err error) {
+	f, err := openReader(r, newConfig(opts))
+	if err != nil {
+		return nil, err
+	}
+	defer closeFile(f, &err)
+	return ReadWorkbookFromFileContext[T](ctx, f, opts...)
+}
+
+// Read an opened workbook to a struct, see ReadWorkbook. The workbook is not closed
+func ReadWorkbookFromFile[T any](f *excelize.File, opts ...Option) (*T, error) {
+	return ReadWorkbookFromFileContext[T](context.Background(), f, opts...)
+}
+
+// Read an opened workbook to a struct, see ReadWorkbook. The reading is aborted with the error of the ctx when the ctx is done
+func ReadWorkbookFromFileContext[T any](ctx context.Context, f *excelize.File, opts ...Option) (*T, error) {
+	result := new(T)
+	if err := readWorkbook(ctx, f, reflect.ValueOf(result).Elem(), newConfig(opts)); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// sheetJob is a sheet read into a slice field of the workbook struct
+type sheetJob struct {
+	fieldIndex int
+	fieldName  string
+	elemType   reflect.Type
+	sheetName  string
+	data       reflect.Value
+	err        error
+}
+
+// Read every sheet selected by the slice fields of the workbook struct. The sheets are read by
+// at most WithConcurrency workers sharing the workbook, and the results are set in the order of the fields.
+func readWorkbook(ctx context.Context, f *excelize.File, v reflect.Value, cfg *config) error {
+	t := v.Type()
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("the type should be a struct, the current type is %s", t.String())
+	}
+	var jobs []*sheetJob
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup(sheetTag)
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+		if field.Type.Kind() != reflect.Slice || field.Type.Elem().Kind() != reflect.Struct {
+			return fmt.Errorf("the type of field=%s should be a slice of struct, the current type is %s", field.Name, field.Type.String())
+		}
+		if !ok || strings.TrimSpace(tag) == "" {
+			tag = field.Name
+		}
+		job := &sheetJob{fieldIndex: i, fieldName: field.Name, elemType: field.Type.Elem()}
+		job.sheetName, job.err = getSheetName(f, tag)
+		jobs = append(jobs, job)
+	}
+
+	workers := make(chan struct{}, max(cfg.concurrency, 1))
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		if job.err != nil {
+			continue
+		}
+		workers <- struct{}{}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(job *sheetJob) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			job.data, job.err = readSheet(ctx, f, job.sheetName, job.elemType, cfg)
+		}(job)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	report := new(WorkbookError)
+	for _, job := range jobs {
+		if job.data.IsValid() {
+			// with the collectErrors option, the decoded rows are kept even if some rows failed
+			v.Field(job.fieldIndex).Set(job.data)
+		}
+		if job.err != nil {
+			report.Sheets = append(report.Sheets, &SheetError{FieldName: job.fieldName, SheetName: job.sheetName, Err: job.err})
+		}
+	}
+	if len(report.Sheets) > 0 {
+		return report
+	}
+	return nil
+}
+
+// use to convert the x-sheet tag to the right sheet name
+func getSheetName(f *excelize.File, tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	sheetList := f.GetSheetList()
+	switch {
+	case strings.HasPrefix(tag, "[") && strings.HasSuffix(tag, "]"):
+		indexStr := strings.TrimSpace(tag[1 : len(tag)-1])
+		if indexStr == "" {
+			// if sheet tag declared as '[]', set the first sheet as the default sheetName
+			indexStr = "0"
+		}
+		index, err := strconv.Atoi(indexStr)
+		if err != nil {
+			return "", fmt.Errorf("the sheet tag declared in '[]' is not a number. %w", err)
+		}
+		if index < 0 || index >= len(sheetList) {
+			return "", fmt.Errorf("%w: the sheet tag declared in '[%d]' is out of the sheet count.", ErrSheetNotFound, index)
+		}
+		return sheetList[index], nil
+	case strings.HasPrefix(tag, "re:"):
+		re, err := regexp.Compile(strings.TrimPrefix(tag, "re:"))
+		if err != nil {
+			return "", fmt.Errorf("the sheet tag declared in 're:' is not a valid regular expression. %w", err)
+		}
+		for _, name := range sheetList {
+			if re.MatchString(name) {
+				return name, nil
+			}
+		}
+		return "", fmt.Errorf("%w: no sheet matches the pattern %s", ErrSheetNotFound, re.String())
+	}
+	for _, name := range sheetList {
+		if name == tag {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("%w: No sheet with the specified name exists. %s", ErrSheetNotFound, tag)
+}
@@ -0,0 +1,298 @@
+package excel
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Write the data to the sheet, the first row is the header built from the x-write tag.
+// The file is created when it does not exist, and an existing sheet with the same name is overwritten.
+func WriteToSheet[T any](filepath string, sheetName string, rows []T, opts ...Option) (err error) {
+	t := reflect.TypeOf(new(T)).Elem()
+	s, err := schemaOf(t)
+	if err != nil {
+		return err
+	}
+	cfg := newConfig(opts)
+	columns, err := initWriteColumns(s, cfg)
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("no field of %s is tagged with %s or %s", t.String(), writeTag, readTag)
+	}
+
+	f, isNewFile, err := openOrCreateFile(filepath)
+	if err != nil {
+		return err
+	}
+	defer closeFile(f, &err)
+
+	if err = prepareSheet(f, sheetName, isNewFile); err != nil {
+		return err
+	}
+	sw, err := f.NewStreamWriter(sheetName)
+	if err != nil {
+		return fmt.Errorf("can't write the sheet with the sheetName = %s: %w", sheetName, err)
+	}
+
+	// write the header row
+	header := make([]interface{}, len(columns))
+	for i, col := range columns {
+		header[i] = col.ColName
+	}
+	if err = sw.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	// write the data rows
+	styles := &cellStyles{f: f}
+	for idx := range rows {
+		v := reflect.ValueOf(&rows[idx]).Elem()
+		cells := make([]interface{}, len(columns))
+		for i, col := range columns {
+			field, ok := fieldByIndex(v, col.Index, false)
+			// the field of a nil nested struct is an empty cell
+			if !ok || col.tag.has(omitemptyOption) && field.IsZero() {
+				continue
+			}
+			if sep, ok := col.split(); ok {
+				cells[i], err = joinCellValues(field, sep, col, cfg)
+			} else if cells[i], err = getCellValue(field, col, cfg); err == nil {
+				cells[i], err = styles.apply(cells[i])
+			}
+			if err != nil {
+				return fmt.Errorf("field=%s, %w", col.FieldName, err)
+			}
+		}
+		axis, _ := excelize.CoordinatesToCellName(1, idx+2)
+		if err = sw.SetRow(axis, cells); err != nil {
+			return err
+		}
+	}
+	if err = sw.Flush(); err != nil {
+		return err
+	}
+	return f.SaveAs(filepath)
+}
+
+// open the Excel file if it exists, otherwise create a new one
+func openOrCreateFile(filepath string) (*excelize.File, bool, error) {
+	_, err := os.Stat(filepath)
+	if errors.Is(err, os.ErrNotExist) {
+		return excelize.NewFile(), true, nil
+	}
+	f, err := excelize.OpenFile(filepath)
+	if err != nil {
+		return nil, false, fmt.Errorf("file opening failed. %s: %w", filepath, err)
+	}
+	return f, false, nil
+}
+
+// make sure the sheet exists, the default sheet of a new file is renamed to the sheetName
+func prepareSheet(f *excelize.File, sheetName string, isNewFile bool) error {
+	idx, err := f.GetSheetIndex(sheetName)
+	if err != nil {
+		return err
+	}
+	if idx != -1 {
+		return nil
+	}
+	if isNewFile {
+		return f.SetSheetName(f.GetSheetName(0), sheetName)
+	}
+	_, err = f.NewSheet(sheetName)
+	return err
+}
+
+// build the columns to write, the order of the columns is the order of the fields
+func initWriteColumns(s *schema, cfg *config) ([]*FieldMappingItem, error) {
+	columns := make([]*FieldMappingItem, 0, len(s.writeFields))
+	for _, field := range s.writeFields {
+		item := newFieldMappingItem(field, len(columns), field.tag.aliases[0])
+		var err error
+		if item.converter, err = cfg.converters.lookup(item); err != nil {
+			return nil, err
+		}
+		columns = append(columns, item)
+	}
+	return columns, nil
+}
+
+// the column name is the x-write tag, falling back to the first alias of the x-read tag that is not a regular expression
+// or the catch-all alias.
+// the options of the x-read tag apply to the writing too, unless they are overridden by the x-write tag.
+// a field tagged with x-write:"-" is not written, and the tag has no aliases when the field has no column name.
+func getWriteTag(field reflect.StructField) *fieldTag {
+	readFieldTag := parseFieldTag(field.Tag.Get(readTag))
+	writeFieldTag := parseFieldTag(field.Tag.Get(writeTag))
+	if len(writeFieldTag.aliases) > 0 && writeFieldTag.aliases[0] == "-" {
+		return nil
+	}
+	if len(writeFieldTag.aliases) == 0 {
+		for _, alias := range readFieldTag.aliases {
+			if !strings.HasPrefix(alias, regexAliasPrefix) && alias != catchAllAlias {
+				writeFieldTag.aliases = append(writeFieldTag.aliases, alias)
+			}
+		}
+	}
+	for key, value := range readFieldTag.options {
+		if _, ok := writeFieldTag.options[key]; !ok {
+			writeFieldTag.options[key] = value
+		}
+	}
+	return writeFieldTag
+}
+
+// convert the field value to a value that the stream writer can write to the cell, the registered converter
+// is used first, then the field types implementing CellMarshaler or encoding.TextMarshaler encode themselves.
+// A nil pointer is written as an empty cell. The dates are written in the location of WithLocation,
+// or as text formatted by the layout option of the tag.
+func getCellValue(value reflect.Value, item *FieldMappingItem, cfg *config) (interface{}, error) {
+	conv := item.converter
+	if value.Kind() == reflect.Pointer && (conv == nil || conv.typ != value.Type()) {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+	if conv != nil && conv.encode != nil {
+		return conv.encode(value)
+	}
+	if marshaler, ok := asInterface[CellMarshaler](value); ok {
+		return marshaler.MarshalCell()
+	}
+	// time.Time is a TextMarshaler, but it is written as a date
+	if marshaler, ok := asInterface[encoding.TextMarshaler](value); ok && value.Type() != timeType {
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return string(text), nil
+	}
+	layout, _ := item.tag.option(layoutOption)
+	switch v := value.Interface().(type) {
+	case time.Time:
+		if v.IsZero() {
+			return nil, nil
+		}
+		if cfg.location != nil {
+			v = v.In(cfg.location)
+		}
+		if layout != "" {
+			return v.Format(layout), nil
+		}
+		return v, nil
+	case Date:
+		if v.IsZero() {
+			return nil, nil
+		}
+		if layout != "" {
+			return v.In(time.UTC).Format(layout), nil
+		}
+		return v, nil
+	case time.Duration:
+		return v, nil
+	}
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return value.Uint(), nil
+	case reflect.Float32:
+		// keep the shortest decimal of the float32, such as 0.1 instead of 0.10000000149011612
+		return strconv.ParseFloat(strconv.FormatFloat(value.Float(), 'g', -1, 32), 64)
+	case reflect.Float64:
+		return value.Float(), nil
+	case reflect.Bool:
+		return value.Bool(), nil
+	}
+	return nil, fmt.Errorf("the type %s can't be written to a cell", value.Type().String())
+}
+
+// join the elements of the slice field to a cell by the separator, every element is converted like a single cell
+func joinCellValues(value reflect.Value, sep string, item *FieldMappingItem, cfg *config) (interface{}, error) {
+	parts := make([]string, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		cellValue, err := getCellValue(value.Index(i), item, cfg)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, formatCellText(cellValue))
+	}
+	return strings.Join(parts, sep), nil
+}
+
+// the text of a cell value, the dates are formatted to be parsed back by the dateLayouts
+func formatCellText(cellValue interface{}) string {
+	switch v := cellValue.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case Date:
+		return v.String()
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 && v.Nanosecond() == 0 {
+			return v.Format(time.DateOnly)
+		}
+		return v.Format(time.DateTime)
+	}
+	return fmt.Sprint(cellValue)
+}
+
+// the styles of the date and the duration cells, they are created on the first use
+type cellStyles struct {
+	f        *excelize.File
+	date     int
+	duration int
+}
+
+// style the Date as a date without the clock, and the time.Duration as the elapsed time such as 36:30:00
+func (s *cellStyles) apply(cellValue interface{}) (interface{}, error) {
+	var err error
+	switch v := cellValue.(type) {
+	case Date:
+		if s.date == 0 {
+			// the built-in format 14 is the short date of the locale
+			if s.date, err = s.f.NewStyle(&excelize.Style{NumFmt: 14}); err != nil {
+				return nil, err
+			}
+		}
+		return excelize.Cell{StyleID: s.date, Value: v.In(time.UTC)}, nil
+	case time.Duration:
+		if s.duration == 0 {
+			numFmt := "[h]:mm:ss"
+			if s.duration, err = s.f.NewStyle(&excelize.Style{CustomNumFmt: &numFmt}); err != nil {
+				return nil, err
+			}
+		}
+		return excelize.Cell{StyleID: s.duration, Value: v.Hours() / 24}, nil
+	}
+	return cellValue, nil
+}
+
+// the value as the interface I, the methods with a pointer receiver are found when the value is addressable
+func asInterface[I any](value reflect.Value) (I, bool) {
+	if i, ok := value.Interface().(I); ok {
+		return i, true
+	}
+	if value.CanAddr() {
+		i, ok := value.Addr().Interface().(I)
+		return i, ok
+	}
+	var zero I
+	return zero, false
+}
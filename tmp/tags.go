@@ -1,7 +0,0 @@
-package tmp
-
-const (
-	readTag  string = "x-read"
-	writeTag string = "x-write"
-	sheetTag string = "x-sheet"
-)